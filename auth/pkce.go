@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	authURL  = "https://accounts.spotify.com/authorize"
+	tokenURL = "https://accounts.spotify.com/api/token"
+)
+
+// DefaultScopes covers what the harvester needs beyond the public catalog:
+// the user's own playlists (including private/collaborative ones) and the
+// ability to follow/create playlists.
+var DefaultScopes = []string{
+	"playlist-read-private",
+	"playlist-read-collaborative",
+	"playlist-modify-public",
+	"playlist-modify-private",
+}
+
+// PKCEFlow drives the Authorization Code + PKCE exchange: it starts a local
+// callback server, opens the consent URL for the user, and exchanges the
+// returned code for a token. A successful flow's token is handed to the
+// caller to persist via a TokenStore.
+type PKCEFlow struct {
+	ClientID    string
+	RedirectURI string
+	Scopes      []string
+}
+
+// Authenticate runs the full flow and returns the resulting token. onConsentURL
+// is invoked with the consent URL the user must open in a browser.
+func (f *PKCEFlow) Authenticate(ctx context.Context, onConsentURL func(url string)) (*oauth2.Token, error) {
+	verifier, challenge, err := newPKCEPair()
+	if err != nil {
+		return nil, fmt.Errorf("generate pkce pair: %w", err)
+	}
+
+	state, err := randomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("generate state: %w", err)
+	}
+
+	cfg := &oauth2.Config{
+		ClientID:    f.ClientID,
+		RedirectURL: f.RedirectURI,
+		Scopes:      f.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  authURL,
+			TokenURL: tokenURL,
+		},
+	}
+
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+	srv, listenErr := startCallbackServer(f.RedirectURI, state, codeCh, errCh)
+	if listenErr != nil {
+		return nil, fmt.Errorf("start callback server: %w", listenErr)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	consentURL := cfg.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		oauth2.SetAuthURLParam("code_challenge", challenge),
+	)
+	onConsentURL(consentURL)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err := <-errCh:
+		return nil, err
+	case code := <-codeCh:
+		tok, err := cfg.Exchange(ctx, code,
+			oauth2.SetAuthURLParam("code_verifier", verifier),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("exchange code: %w", err)
+		}
+		return tok, nil
+	}
+}
+
+// HTTPClient builds an http.Client that transparently refreshes tok and
+// persists the refreshed token via store whenever it rotates.
+func HTTPClient(ctx context.Context, clientID string, tok *oauth2.Token, store TokenStore) *http.Client {
+	cfg := &oauth2.Config{
+		ClientID: clientID,
+		Endpoint: oauth2.Endpoint{AuthURL: authURL, TokenURL: tokenURL},
+	}
+	src := &persistingTokenSource{
+		inner: cfg.TokenSource(ctx, tok),
+		store: store,
+		last:  tok,
+	}
+	return oauth2.NewClient(ctx, src)
+}
+
+// persistingTokenSource wraps an oauth2.TokenSource and writes every newly
+// minted token back to the store, so a refreshed access token survives a
+// process restart without asking the user to re-consent.
+type persistingTokenSource struct {
+	inner oauth2.TokenSource
+	store TokenStore
+	last  *oauth2.Token
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.inner.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok.AccessToken != s.last.AccessToken {
+		s.last = tok
+		if err := s.store.Save(tok); err != nil {
+			log.Printf("persist refreshed token: %v", err)
+		}
+	}
+	return tok, nil
+}
+
+func startCallbackServer(redirectURI, expectedState string, codeCh chan<- string, errCh chan<- error) (*http.Server, error) {
+	addr, path, err := parseRedirectURI(redirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if errParam := r.URL.Query().Get("error"); errParam != "" {
+			errCh <- fmt.Errorf("spotify denied consent: %s", errParam)
+			fmt.Fprintln(w, "Authentication failed, you can close this tab.")
+			return
+		}
+		if state := r.URL.Query().Get("state"); state != expectedState {
+			errCh <- fmt.Errorf("state mismatch in callback")
+			fmt.Fprintln(w, "Authentication failed, you can close this tab.")
+			return
+		}
+		code := r.URL.Query().Get("code")
+		if code == "" {
+			errCh <- fmt.Errorf("callback missing code parameter")
+			fmt.Fprintln(w, "Authentication failed, you can close this tab.")
+			return
+		}
+		fmt.Fprintln(w, "Authentication complete, you can close this tab.")
+		codeCh <- code
+	})
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	srv := &http.Server{Handler: mux}
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+	return srv, nil
+}
+
+func parseRedirectURI(redirectURI string) (addr, path string, err error) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", "", err
+	}
+	host := u.Host
+	if host == "" {
+		host = "127.0.0.1:8888"
+	}
+	p := u.Path
+	if p == "" {
+		p = "/callback"
+	}
+	return host, p, nil
+}
+
+func newPKCEPair() (verifier, challenge string, err error) {
+	verifier, err = randomString(64)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	challenge = base64.RawURLEncoding.EncodeToString(sum[:])
+	return verifier, challenge, nil
+}
+
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
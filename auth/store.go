@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenStore persists an oauth2.Token across runs so the user-auth flow only
+// has to happen once; subsequent runs load the refresh token and let
+// golang.org/x/oauth2 silently mint new access tokens.
+type TokenStore interface {
+	Load() (*oauth2.Token, error)
+	Save(tok *oauth2.Token) error
+}
+
+// FileTokenStore persists the token JSON encrypted at rest with AES-GCM. The
+// key is derived from a passphrase (e.g. CLIENT_SECRET) so no separate key
+// file needs to be distributed alongside the token.
+type FileTokenStore struct {
+	path       string
+	passphrase string
+}
+
+// NewFileTokenStore returns a store that reads/writes the encrypted token at
+// path, keyed by passphrase.
+func NewFileTokenStore(path, passphrase string) *FileTokenStore {
+	return &FileTokenStore{path: path, passphrase: passphrase}
+}
+
+// Load reads and decrypts the token at path. It returns (nil, nil) if no
+// token has been persisted yet.
+func (s *FileTokenStore) Load() (*oauth2.Token, error) {
+	ciphertext, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read token store: %w", err)
+	}
+
+	plaintext, err := decrypt(ciphertext, s.key())
+	if err != nil {
+		return nil, fmt.Errorf("decrypt token store: %w", err)
+	}
+
+	var tok oauth2.Token
+	if err := json.Unmarshal(plaintext, &tok); err != nil {
+		return nil, fmt.Errorf("parse token store: %w", err)
+	}
+	return &tok, nil
+}
+
+// Save encrypts tok and writes it to path, creating parent directories as
+// needed.
+func (s *FileTokenStore) Save(tok *oauth2.Token) error {
+	plaintext, err := json.Marshal(tok)
+	if err != nil {
+		return fmt.Errorf("marshal token: %w", err)
+	}
+
+	ciphertext, err := encrypt(plaintext, s.key())
+	if err != nil {
+		return fmt.Errorf("encrypt token: %w", err)
+	}
+
+	if dir := filepath.Dir(s.path); dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("create token store dir: %w", err)
+		}
+	}
+	return os.WriteFile(s.path, ciphertext, 0o600)
+}
+
+func (s *FileTokenStore) key() [32]byte {
+	return sha256.Sum256([]byte(s.passphrase))
+}
+
+func encrypt(plaintext []byte, key [32]byte) ([]byte, error) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(ciphertext []byte, key [32]byte) ([]byte, error) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, body := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, body, nil)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// tokenProvider supplies the bearer token spotifyClient.execute sends with
+// a request to route (a stable endpoint name like "search" or "playlist",
+// not the literal path - which varies per playlist ID). This lets a client
+// swap between client-credentials and scraped web-session tokens without
+// call sites caring which is currently active.
+type tokenProvider interface {
+	Token(ctx context.Context, route string) (string, error)
+}
+
+// degradable is implemented by tokenProviders that can react to a route's
+// token being rejected. MarkDegraded returns true the first time route is
+// marked, so execute retries exactly once per route rather than looping
+// forever against an endpoint that's broken outright.
+type degradable interface {
+	MarkDegraded(route string) bool
+}
+
+// newTokenProvider builds the tokenProvider selected by --auth-mode.
+func newTokenProvider(mode, clientID, clientSecret string) (tokenProvider, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "client":
+		return newClientCredentialsProvider(clientID, clientSecret), nil
+	case "web":
+		return newWebSessionProvider(), nil
+	case "auto":
+		return newAutoTokenProvider(newClientCredentialsProvider(clientID, clientSecret), newWebSessionProvider()), nil
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q (want client, web or auto)", mode)
+	}
+}
+
+// clientCredentialsProvider wraps Spotify's Client Credentials Flow,
+// requesting a fresh token via requestClientCredentialsToken once the
+// current one is within a minute of expiring.
+type clientCredentialsProvider struct {
+	clientID, clientSecret string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func newClientCredentialsProvider(clientID, clientSecret string) *clientCredentialsProvider {
+	return &clientCredentialsProvider{clientID: clientID, clientSecret: clientSecret}
+}
+
+func (p *clientCredentialsProvider) Token(ctx context.Context, route string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && time.Now().Before(p.expiresAt) {
+		return p.token, nil
+	}
+	token, expiresIn, err := requestClientCredentialsToken(p.clientID, p.clientSecret)
+	if err != nil {
+		return "", fmt.Errorf("request client-credentials token: %w", err)
+	}
+	p.token = token
+	p.expiresAt = time.Now().Add(time.Duration(expiresIn)*time.Second - 60*time.Second)
+	return p.token, nil
+}
+
+// webSessionTokenPattern and webSessionExpiryPattern pull the accessToken
+// and its expiry out of the JSON blob open.spotify.com embeds in its HTML
+// for an anonymous session, without needing a full HTML/JS parser.
+var (
+	webSessionTokenPattern  = regexp.MustCompile(`"accessToken":"([^"]+)"`)
+	webSessionExpiryPattern = regexp.MustCompile(`"accessTokenExpirationTimestampMs":(\d+)`)
+)
+
+// webSessionProvider scrapes an anonymous access token from
+// open.spotify.com, for endpoints Spotify has locked away from client
+// credentials but still serves to a logged-out browser session.
+type webSessionProvider struct {
+	rest *resty.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func newWebSessionProvider() *webSessionProvider {
+	return &webSessionProvider{rest: resty.New().SetTimeout(15 * time.Second)}
+}
+
+func (p *webSessionProvider) Token(ctx context.Context, route string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && time.Now().Before(p.expiresAt) {
+		return p.token, nil
+	}
+	resp, err := p.rest.R().SetContext(ctx).Get("https://open.spotify.com")
+	if err != nil {
+		return "", fmt.Errorf("fetch open.spotify.com: %w", err)
+	}
+	if resp.IsError() {
+		return "", fmt.Errorf("fetch open.spotify.com failed with status %d", resp.StatusCode())
+	}
+	token, expiresAt, err := parseWebSessionToken(resp.Body())
+	if err != nil {
+		return "", err
+	}
+	p.token = token
+	p.expiresAt = expiresAt.Add(-60 * time.Second)
+	return p.token, nil
+}
+
+// parseWebSessionToken extracts accessToken and
+// accessTokenExpirationTimestampMs from an open.spotify.com HTML response.
+func parseWebSessionToken(body []byte) (token string, expiresAt time.Time, err error) {
+	tm := webSessionTokenPattern.FindSubmatch(body)
+	if tm == nil {
+		return "", time.Time{}, errors.New("accessToken not found in open.spotify.com response")
+	}
+	em := webSessionExpiryPattern.FindSubmatch(body)
+	if em == nil {
+		return "", time.Time{}, errors.New("accessTokenExpirationTimestampMs not found in open.spotify.com response")
+	}
+	ms, err := strconv.ParseInt(string(em[1]), 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("parse accessTokenExpirationTimestampMs: %w", err)
+	}
+	return string(tm[1]), time.UnixMilli(ms), nil
+}
+
+// autoTokenProvider starts every route on the client-credentials provider
+// (cheaper - no scraping) and remembers which routes were rejected with a
+// 401/404 under it, serving the web-session token to those routes from
+// then on.
+type autoTokenProvider struct {
+	client *clientCredentialsProvider
+	web    *webSessionProvider
+
+	mu       sync.Mutex
+	degraded map[string]struct{}
+}
+
+func newAutoTokenProvider(client *clientCredentialsProvider, web *webSessionProvider) *autoTokenProvider {
+	return &autoTokenProvider{client: client, web: web, degraded: make(map[string]struct{})}
+}
+
+func (p *autoTokenProvider) Token(ctx context.Context, route string) (string, error) {
+	p.mu.Lock()
+	_, degraded := p.degraded[route]
+	p.mu.Unlock()
+	if degraded {
+		return p.web.Token(ctx, route)
+	}
+	return p.client.Token(ctx, route)
+}
+
+func (p *autoTokenProvider) MarkDegraded(route string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.degraded[route]; ok {
+		return false
+	}
+	p.degraded[route] = struct{}{}
+	return true
+}
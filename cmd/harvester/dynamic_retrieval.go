@@ -0,0 +1,1799 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/joho/godotenv"
+	"golang.org/x/time/rate"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/metadata"
+	"github.com/kirbs-btw/spotify-playlist-dataset/spotifyid"
+	"github.com/kirbs-btw/spotify-playlist-dataset/store"
+)
+
+type harvestSeeds struct {
+	Keywords []string     `json:"keywords"`
+	Genres   []string     `json:"genres"`
+	Moods    []string     `json:"moods"`
+	Meta     []string     `json:"meta"`
+	Locales  []string     `json:"locales"`
+	Artists  []seedArtist `json:"artists"`
+	Tracks   []seedTrack  `json:"tracks"`
+}
+
+type seedArtist struct {
+	Name        string              `json:"name"`
+	Aliases     []string            `json:"aliases"`
+	SpotifyID   spotifyid.ArtistID  `json:"spotify_id"`
+	TopTrackIDs []spotifyid.TrackID `json:"top_track_ids"`
+}
+
+type seedTrack struct {
+	ID   spotifyid.TrackID `json:"id"`
+	Name string            `json:"name"`
+}
+
+type seedQuery struct {
+	Query  string
+	Source string
+}
+
+type playlistDetail struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Public        bool   `json:"public"`
+	Collaborative bool   `json:"collaborative"`
+	Owner         struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+	} `json:"owner"`
+	SnapshotID string `json:"snapshot_id"`
+	Followers  struct {
+		Total int `json:"total"`
+	} `json:"followers"`
+	Images []struct {
+		URL    string `json:"url"`
+		Height int    `json:"height"`
+		Width  int    `json:"width"`
+	} `json:"images"`
+	Tracks struct {
+		Total int `json:"total"`
+	} `json:"tracks"`
+}
+
+type playlistSimple struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Owner       struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+	} `json:"owner"`
+	SnapshotID string `json:"snapshot_id"`
+	Tracks     struct {
+		Total int `json:"total"`
+	} `json:"tracks"`
+}
+
+type playlistPage struct {
+	Items []playlistSimple `json:"items"`
+	Next  string           `json:"next"`
+}
+
+type searchResponse struct {
+	Playlists playlistPage `json:"playlists"`
+}
+
+type playlistPageResponse struct {
+	Playlists playlistPage `json:"playlists"`
+}
+
+type featuredResponse struct {
+	Message   string       `json:"message"`
+	Playlists playlistPage `json:"playlists"`
+}
+
+type categoryList struct {
+	Categories struct {
+		Items []category `json:"items"`
+		Next  string     `json:"next"`
+	} `json:"categories"`
+}
+
+type category struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type playlistTracksPage struct {
+	Items []playlistTrackItem `json:"items"`
+	Next  string              `json:"next"`
+}
+
+type playlistTrackItem struct {
+	AddedAt string `json:"added_at"`
+	AddedBy struct {
+		ID string `json:"id"`
+	} `json:"added_by"`
+	Track struct {
+		ID           string            `json:"id"`
+		Name         string            `json:"name"`
+		URI          string            `json:"uri"`
+		DurationMs   int               `json:"duration_ms"`
+		ExternalUrls map[string]string `json:"external_urls"`
+		ExternalIDs  struct {
+			ISRC string `json:"isrc"`
+		} `json:"external_ids"`
+		Artists []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"artists"`
+		Album struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"album"`
+	} `json:"track"`
+}
+
+type harvestOrigin struct {
+	Source string
+	Query  string
+}
+
+type relevanceResult struct {
+	Score          float64
+	KeywordMatches []string
+	ArtistMatches  []string
+	TrackMatches   []string
+	FreshnessDays  int
+	FollowerBoost  float64
+	FreshnessBoost float64
+	// Components holds each scoring factor's contribution to Score
+	// (e.g. "keyword_bm25", "artist", "track", "follower", "freshness"),
+	// so --explain can dump per-factor detail without callers needing to
+	// re-derive it from the fields above.
+	Components map[string]float64
+}
+
+type seedIndex struct {
+	keywords     []string
+	artistByName map[string]seedArtist
+	artistByID   map[string]seedArtist
+	trackByID    map[string]seedTrack
+}
+
+type csvStore struct {
+	file   *os.File
+	writer *csv.Writer
+	mu     sync.Mutex
+}
+
+// snapshotStore tracks the last-seen snapshot_id per playlist so a harvest
+// can skip playlists Spotify reports as unchanged. snapshotCache is the
+// original JSON-file-backed implementation; sqliteSnapshotStore delegates
+// to the store.DataStore SnapshotRepository when --export includes sqlite.
+type snapshotStore interface {
+	IsUnchanged(id, snapshot string) bool
+	// Existed reports whether a snapshot was ever recorded for id before
+	// this harvest pass, so a write can be classified as "new" vs
+	// "updated" in the per-run summary.
+	Existed(id string) bool
+	Update(id, snapshot string)
+	Save() error
+}
+
+type snapshotCache struct {
+	path  string
+	mu    sync.Mutex
+	data  map[string]string
+	dirty bool
+}
+
+// rateLimiter is a hand-rolled token bucket: it lets execute burst up to
+// capacity tokens and refills at rate tokens/sec, rather than forcing every
+// request through a fixed interval even when Spotify's quota is idle. On a
+// 429 it drains the bucket and halves rate (AIMD backoff); on a run of
+// successes at a throttled rate it recovers additively back towards
+// baseRate. This keeps the harvester's actual throughput close to whatever
+// Spotify's rolling 30s window will bear instead of a static guess.
+type rateLimiter struct {
+	mu                 sync.Mutex
+	tokens             float64
+	capacity           float64
+	rate               float64
+	baseRate           float64
+	minRate            float64
+	lastRefill         time.Time
+	successSinceAdjust int
+}
+
+// rateRecoverySuccesses is how many consecutive successful requests at a
+// throttled rate are required before additively nudging the rate back up.
+const rateRecoverySuccesses = 20
+
+func newRateLimiter(ratePerSec float64) *rateLimiter {
+	if ratePerSec <= 0 {
+		ratePerSec = 5.0
+	}
+	capacity := math.Max(1, ratePerSec)
+	return &rateLimiter{
+		tokens:     capacity,
+		capacity:   capacity,
+		rate:       ratePerSec,
+		baseRate:   ratePerSec,
+		minRate:    ratePerSec / 8,
+		lastRefill: time.Now(),
+	}
+}
+
+func (r *rateLimiter) Wait(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		r.mu.Lock()
+		r.refillLocked()
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (r *rateLimiter) refillLocked() {
+	now := time.Now()
+	elapsed := now.Sub(r.lastRefill).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	r.tokens = math.Min(r.capacity, r.tokens+elapsed*r.rate)
+	r.lastRefill = now
+}
+
+// OnRateLimited applies the multiplicative half of AIMD: it's called when
+// Spotify returns a 429, in addition to honoring Retry-After.
+func (r *rateLimiter) OnRateLimited() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = 0
+	r.rate = math.Max(r.minRate, r.rate/2)
+	r.successSinceAdjust = 0
+	log.Printf("rate limiter backing off to %.2f req/s after a 429", r.rate)
+}
+
+// OnSuccess applies the additive increase half of AIMD, nudging a
+// previously-throttled rate back towards baseRate once it's proven stable.
+func (r *rateLimiter) OnSuccess() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rate >= r.baseRate {
+		return
+	}
+	r.successSinceAdjust++
+	if r.successSinceAdjust < rateRecoverySuccesses {
+		return
+	}
+	r.successSinceAdjust = 0
+	r.rate = math.Min(r.baseRate, r.rate+r.baseRate*0.1)
+	log.Printf("rate limiter recovering to %.2f req/s", r.rate)
+}
+
+// Stats returns the current refill rate and available tokens, for the
+// periodic debug log line in logLimiterStats.
+func (r *rateLimiter) Stats() (currentRate, tokens, capacity float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refillLocked()
+	return r.rate, r.tokens, r.capacity
+}
+
+type spotifyClient struct {
+	rest      *resty.Client
+	tokens    tokenProvider
+	limiter   *rateLimiter
+	statsDone chan struct{}
+	requests  int64
+}
+
+type harvester struct {
+	client     *spotifyClient
+	seeds      *harvestSeeds
+	index      *seedIndex
+	sinks      []PlaylistSink
+	snapshots  snapshotStore
+	seedRepo   store.SeedRepository // nil unless --export includes sqlite
+	metadata   metadata.Source      // nil unless --enable-musicbrainz
+	enrichment *csvStore            // nil unless --enable-musicbrainz
+	explain    *csvStore            // nil unless --explain
+	// bm25 tracks the running avgdl/IDF estimates computeRelevance's BM25
+	// keyword score is scored against, updated as each playlist is
+	// observed over the course of a run.
+	bm25 *bm25Index
+	seen map[string]struct{}
+	mu   sync.Mutex
+	// limiter paces playlist-processing job dispatch across harvestSearch,
+	// harvestFeatured and harvestCategories alike, on top of (not instead
+	// of) the spotifyClient's own per-request AIMD rateLimiter, so bursts
+	// of concurrent workers can't all hit processPlaylist at once.
+	limiter *rate.Limiter
+	opts    harvestOptions
+	stats   harvestStats
+}
+
+// jobRate and jobBurst bound the shared token bucket every concurrent
+// playlist-processing worker waits on before calling processPlaylist.
+const (
+	jobRate  = 10
+	jobBurst = 20
+)
+
+// harvestStats tallies one harvestSearch+harvestFeatured+harvestCategories
+// pass, so a scheduled, long-running process can report what each run
+// actually did instead of just that it ran.
+type harvestStats struct {
+	mu              sync.Mutex
+	Scanned         int
+	Written         int
+	New             int
+	Updated         int
+	SkippedSnapshot int
+	SkippedScore    int
+	Errors          int
+}
+
+func (s *harvestStats) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Scanned, s.Written, s.New, s.Updated, s.SkippedSnapshot, s.SkippedScore, s.Errors = 0, 0, 0, 0, 0, 0, 0
+}
+
+// snapshotWritten returns the current Written count, for the search
+// harvest's periodic progress line.
+func (s *harvestStats) snapshotWritten() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Written
+}
+
+func (s *harvestStats) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("scanned=%d written=%d new=%d updated=%d skipped_by_snapshot=%d skipped_by_score=%d errors=%d",
+		s.Scanned, s.Written, s.New, s.Updated, s.SkippedSnapshot, s.SkippedScore, s.Errors)
+}
+
+type harvestOptions struct {
+	ScoreThreshold    float64
+	MaxSearchPages    int
+	MaxBrowsePages    int
+	IncludeFeatured   bool
+	IncludeCategories bool
+	Workers           int
+	PerSourceCap      int
+	// Concurrency bounds the worker pool harvestFeatured and
+	// harvestCategories drain playlist IDs through; 0 means
+	// runtime.NumCPU().
+	Concurrency       int
+	EnableMusicBrainz bool
+	// Schedule is a standard 5-field cron expression Run keeps re-harvesting
+	// on; empty means run once and return.
+	Schedule string
+
+	// Relevance scoring weights, all multiplied into computeRelevance's
+	// per-factor contribution before summing into relevanceResult.Score.
+	// KeywordWeight scales the BM25 score over seed keywords; the rest
+	// scale the flat bonuses that BM25 doesn't model.
+	KeywordWeight   float64
+	ArtistWeight    float64
+	TrackWeight     float64
+	FollowerWeight  float64
+	FreshnessWeight float64
+	// BM25K1 and BM25B are the standard Okapi BM25 term-frequency
+	// saturation and document-length normalization parameters.
+	BM25K1 float64
+	BM25B  float64
+	// Explain, when set, makes processPlaylist write relevanceResult's
+	// per-component breakdown for every scored playlist to the
+	// harvester's explain CSV, for tuning the weights above.
+	Explain bool
+}
+
+func main() {
+	envFile := flag.String("env", ".env", "Path to .env file with Spotify credentials")
+	playlistOut := flag.String("playlist-out", "data/playlists_dynamic.csv", "CSV to persist harvested playlists")
+	trackOut := flag.String("track-out", "data/playlist_tracks_dynamic.csv", "CSV to persist harvested playlist tracks")
+	seedFile := flag.String("seeds", "", "Optional JSON file with custom seed configuration")
+	stateFile := flag.String("state", "data/dynamic_snapshot_state.json", "Path to snapshot cache file")
+	maxSearchPages := flag.Int("max-search-pages", 6, "Max search result pages per seed query (each page = 50 playlists)")
+	maxBrowsePages := flag.Int("max-browse-pages", 2, "Max browse pages per category/featured list")
+	scoreThreshold := flag.Float64("score-threshold", 2.5, "Minimum relevance score required to persist playlist data")
+	rateLimit := flag.Float64("rate-limit", 7.5, "Max Spotify API requests per second")
+	includeFeatured := flag.Bool("include-featured", true, "Include Spotify featured playlists pass")
+	includeCategories := flag.Bool("include-categories", true, "Include Spotify browse categories pass")
+	exportFormat := flag.String("export-format", "csv", "Comma-separated output formats to populate: csv,m3u8,jspf,jsonl")
+	exportDir := flag.String("export-dir", "data", "Directory the m3u8/jspf exporters write playlist files under")
+	schedule := flag.String("schedule", "", "Standard 5-field cron expression (e.g. \"0 */6 * * *\") to keep running and re-harvest on; leave empty to run once and exit")
+	sqlitePath := flag.String("sqlite", "", "Path to the SQLite database used when --export is sqlite or both; defaults to <export-dir>/dataset.sqlite3")
+	exportMode := flag.String("export", "csv", "Dataset backend(s) to populate: csv, sqlite, or both")
+	seedProviderSpec := flag.String("seed-provider", "", "Comma-separated external seed sources, e.g. \"listenbrainz:user=foo,lastfm:tag=shoegaze,m3u:./mylibrary.m3u8\"")
+	workers := flag.Int("workers", 4, "Number of concurrent workers draining the search seed queries")
+	perSourceCap := flag.Int("per-source-cap", 0, "Max queries to run per seed Source (e.g. mood+genre); 0 means unlimited")
+	concurrency := flag.Int("concurrency", runtime.NumCPU(), "Number of concurrent workers draining the featured/categories playlist queues")
+	enableMusicBrainz := flag.Bool("enable-musicbrainz", false, "Resolve harvested tracks against MusicBrainz (ISRC then artist+title fuzzy match) and write data/track_enrichment.csv")
+	musicBrainzCache := flag.String("musicbrainz-cache", "data/musicbrainz_cache.json", "Disk cache of MusicBrainz lookups (including negative hits), shared across runs")
+	enrichmentOut := flag.String("enrichment-out", "data/track_enrichment.csv", "CSV to persist resolved MusicBrainz metadata to, when --enable-musicbrainz is set")
+	keywordWeight := flag.Float64("keyword-weight", 1.0, "Weight applied to the BM25 keyword score")
+	artistWeight := flag.Float64("artist-weight", 1.5, "Weight applied per matched seed artist")
+	trackWeight := flag.Float64("track-weight", 2.0, "Weight applied per matched seed track")
+	followerWeight := flag.Float64("follower-weight", 0.5, "Weight applied to the log10(followers) boost")
+	freshnessWeight := flag.Float64("freshness-weight", 1.0, "Weight applied to the recent-activity boost")
+	bm25K1 := flag.Float64("bm25-k1", 1.2, "BM25 term-frequency saturation parameter")
+	bm25B := flag.Float64("bm25-b", 0.75, "BM25 document-length normalization parameter")
+	explain := flag.Bool("explain", false, "Write each scored playlist's per-factor relevance breakdown to --explain-out, for tuning weights")
+	explainOut := flag.String("explain-out", "data/playlist_score_explain.csv", "CSV to persist per-playlist relevance score components to, when --explain is set")
+	authMode := flag.String("auth-mode", "client", "Spotify auth mode: client (client-credentials token), web (scraped anonymous open.spotify.com session token), or auto (start on client, fall back to web per-endpoint on 401/404)")
+	flag.Parse()
+
+	if err := godotenv.Load(*envFile); err != nil {
+		log.Fatalf("failed to load env file %s: %v", *envFile, err)
+	}
+
+	clientID := strings.TrimSpace(os.Getenv("CLIENT_ID"))
+	clientSecret := strings.TrimSpace(os.Getenv("CLIENT_SECRET"))
+	if strings.ToLower(strings.TrimSpace(*authMode)) != "web" && (clientID == "" || clientSecret == "") {
+		log.Fatal("CLIENT_ID and CLIENT_SECRET must be present in the environment unless --auth-mode=web")
+	}
+
+	tokens, err := newTokenProvider(*authMode, clientID, clientSecret)
+	if err != nil {
+		log.Fatalf("failed to configure spotify auth: %v", err)
+	}
+	if _, err := tokens.Token(context.Background(), "startup"); err != nil {
+		log.Fatalf("failed to obtain initial spotify token: %v", err)
+	}
+
+	seeds, err := loadSeedsWithFallback(*seedFile)
+	if err != nil {
+		log.Fatalf("failed to load seeds: %v", err)
+	}
+
+	if *seedProviderSpec != "" {
+		providers, err := parseSeedProviders(*seedProviderSpec)
+		if err != nil {
+			log.Fatalf("failed to configure seed providers: %v", err)
+		}
+		seeds = loadProviderSeeds(context.Background(), seeds, providers)
+	}
+
+	queries := generateSeedQueries(seeds)
+	log.Printf("generated %d seed queries", len(queries))
+
+	useCSV, useSQLite, err := parseExportMode(*exportMode)
+	if err != nil {
+		log.Fatalf("failed to configure export: %v", err)
+	}
+
+	var sinks []PlaylistSink
+	if useCSV {
+		playlistStore, err := newCSVStore(*playlistOut, []string{"playlist_id", "name", "description", "followers", "public", "collaborative", "owner_id", "owner_name", "origin", "query", "score", "matched_keywords", "matched_artists", "matched_tracks", "snapshot_id", "image_url", "track_total", "freshness_days", "last_refreshed_at"})
+		if err != nil {
+			log.Fatalf("failed to open playlist CSV: %v", err)
+		}
+		defer func() {
+			if cerr := playlistStore.Close(); cerr != nil {
+				log.Printf("close playlist CSV: %v", cerr)
+			}
+		}()
+
+		trackStore, err := newCSVStore(*trackOut, []string{"playlist_id", "track_id", "track_name", "artists", "album_id", "added_at", "added_by", "external_url", "origin", "query"})
+		if err != nil {
+			log.Fatalf("failed to open track CSV: %v", err)
+		}
+		defer func() {
+			if cerr := trackStore.Close(); cerr != nil {
+				log.Printf("close track CSV: %v", cerr)
+			}
+		}()
+
+		csvSinks, err := newSinks(*exportFormat, *exportDir, playlistStore, trackStore)
+		if err != nil {
+			log.Fatalf("failed to configure export sinks: %v", err)
+		}
+		sinks = append(sinks, csvSinks...)
+	}
+
+	var snapshots snapshotStore
+	var seedRepo store.SeedRepository
+	if useSQLite {
+		path := *sqlitePath
+		if path == "" {
+			path = filepath.Join(*exportDir, "dataset.sqlite3")
+		}
+		sqlite, err := newSQLiteSink(path)
+		if err != nil {
+			log.Fatalf("failed to open sqlite sink: %v", err)
+		}
+		sinks = append(sinks, sqlite)
+		// The SQLite database already tracks snapshot IDs transactionally
+		// alongside the rows they describe, so prefer it over the JSON
+		// state file for resuming a killed run.
+		snapshots = &sqliteSnapshotStore{ds: sqlite.ds}
+		seedRepo = sqlite.ds.Seed()
+	} else {
+		cache, err := loadSnapshotCache(*stateFile)
+		if err != nil {
+			log.Fatalf("failed to load snapshot cache: %v", err)
+		}
+		snapshots = cache
+	}
+	defer func() {
+		for _, sink := range sinks {
+			if cerr := sink.Close(); cerr != nil {
+				log.Printf("close sink: %v", cerr)
+			}
+		}
+	}()
+	defer func() {
+		if err := snapshots.Save(); err != nil {
+			log.Printf("snapshot store save failed: %v", err)
+		}
+	}()
+
+	client := newSpotifyClient(tokens, *rateLimit)
+	defer client.Close()
+
+	var metadataSource metadata.Source
+	var enrichmentStore *csvStore
+	if *enableMusicBrainz {
+		mb, err := metadata.NewMusicBrainzSource(*musicBrainzCache, "spotify-playlist-dataset/1.0 (+https://github.com/kirbs-btw/spotify-playlist-dataset)")
+		if err != nil {
+			log.Fatalf("failed to configure musicbrainz source: %v", err)
+		}
+		defer func() {
+			if err := mb.Save(); err != nil {
+				log.Printf("musicbrainz cache save failed: %v", err)
+			}
+		}()
+		metadataSource = mb
+
+		enrichmentStore, err = newCSVStore(*enrichmentOut, []string{"track_id", "isrc", "artist_mbid", "release_group_id", "original_release_date", "label", "genres", "match_method", "match_score"})
+		if err != nil {
+			log.Fatalf("failed to open track_enrichment CSV: %v", err)
+		}
+		defer func() {
+			if cerr := enrichmentStore.Close(); cerr != nil {
+				log.Printf("close track_enrichment CSV: %v", cerr)
+			}
+		}()
+	}
+
+	var explainStore *csvStore
+	if *explain {
+		var err error
+		explainStore, err = newCSVStore(*explainOut, []string{"playlist_id", "name", "score", "keyword_bm25", "artist", "track", "follower", "freshness"})
+		if err != nil {
+			log.Fatalf("failed to open playlist_score_explain CSV: %v", err)
+		}
+		defer func() {
+			if cerr := explainStore.Close(); cerr != nil {
+				log.Printf("close playlist_score_explain CSV: %v", cerr)
+			}
+		}()
+	}
+
+	opts := harvestOptions{
+		ScoreThreshold:    *scoreThreshold,
+		MaxSearchPages:    *maxSearchPages,
+		MaxBrowsePages:    *maxBrowsePages,
+		IncludeFeatured:   *includeFeatured,
+		IncludeCategories: *includeCategories,
+		Workers:           *workers,
+		PerSourceCap:      *perSourceCap,
+		Concurrency:       *concurrency,
+		EnableMusicBrainz: *enableMusicBrainz,
+		Schedule:          *schedule,
+		KeywordWeight:     *keywordWeight,
+		ArtistWeight:      *artistWeight,
+		TrackWeight:       *trackWeight,
+		FollowerWeight:    *followerWeight,
+		FreshnessWeight:   *freshnessWeight,
+		BM25K1:            *bm25K1,
+		BM25B:             *bm25B,
+		Explain:           *explain,
+	}
+
+	h := newHarvester(client, seeds, sinks, snapshots, seedRepo, metadataSource, enrichmentStore, explainStore, opts)
+
+	if err := h.Run(context.Background(), queries); err != nil {
+		log.Printf("harvester stopped: %v", err)
+	}
+}
+
+// Run triggers one harvestSearch/harvestFeatured/harvestCategories pass
+// immediately and, if opts.Schedule is set, again every time it fires -
+// akin to Navidrome's schedulePlaylistSync - until ctx is cancelled or
+// SIGINT/SIGTERM is received, at which point it flushes the snapshot store
+// (and, for CSV runs, the still-buffered writers via the caller's own
+// defers) before returning.
+func (h *harvester) Run(ctx context.Context, queries []seedQuery) error {
+	runOnce := func(ctx context.Context) {
+		h.stats.reset()
+		h.runHarvestPass(ctx, queries)
+		log.Printf("harvest pass complete: %s", h.stats.String())
+	}
+
+	if h.opts.Schedule == "" {
+		runOnce(ctx)
+		return nil
+	}
+
+	return runScheduled(ctx, h.opts.Schedule, runOnce, func() {
+		if err := h.snapshots.Save(); err != nil {
+			log.Printf("snapshot store save failed: %v", err)
+		}
+	})
+}
+
+// runHarvestPass runs one search+featured+categories sweep, the unit of work
+// --schedule repeats. Errors from individual sub-passes are logged rather
+// than fatal, so a transient failure in one pass doesn't kill the process
+// the scheduler is meant to keep alive.
+func (h *harvester) runHarvestPass(ctx context.Context, queries []seedQuery) {
+	if err := h.harvestSearch(ctx, queries); err != nil {
+		log.Printf("search harvest encountered errors: %v", err)
+	}
+
+	if h.opts.IncludeFeatured {
+		if err := h.harvestFeatured(ctx); err != nil {
+			log.Printf("featured harvest encountered errors: %v", err)
+		}
+	}
+
+	if h.opts.IncludeCategories {
+		if err := h.harvestCategories(ctx); err != nil {
+			log.Printf("category harvest encountered errors: %v", err)
+		}
+	}
+}
+
+// parseExportMode translates --export into which backends main should wire
+// up: csv (the file-based sinks behind --export-format) and/or sqlite (the
+// store.DataStore-backed sink, which also takes over snapshot tracking).
+func parseExportMode(mode string) (useCSV, useSQLite bool, err error) {
+	switch strings.TrimSpace(strings.ToLower(mode)) {
+	case "csv":
+		return true, false, nil
+	case "sqlite":
+		return false, true, nil
+	case "both":
+		return true, true, nil
+	default:
+		return false, false, fmt.Errorf("unknown export mode %q (want csv, sqlite or both)", mode)
+	}
+}
+
+func loadSeedsWithFallback(path string) (*harvestSeeds, error) {
+	defaults := defaultHarvestSeeds()
+	if path == "" {
+		return defaults, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read seeds file: %w", err)
+	}
+
+	var custom harvestSeeds
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("parse seeds file: %w", err)
+	}
+
+	merged := mergeSeeds(defaults, &custom)
+	return merged, nil
+}
+
+func defaultHarvestSeeds() *harvestSeeds {
+	return &harvestSeeds{
+		Keywords: []string{
+			"fresh finds", "viral", "underground", "discover", "editorial", "playlist", "mix",
+		},
+		Genres: []string{
+			"rock", "indie pop", "techno", "k-pop", "lofi", "hip hop", "latin", "afrobeat", "r&b", "country", "metal", "soul", "jazz", "ambient", "house", "edm",
+		},
+		Moods: []string{
+			"study", "focus", "workout", "party", "chill", "sleep", "meditation", "running", "coding", "gaming", "summer", "relax", "road trip", "dance",
+		},
+		Meta: []string{
+			"best", "top", "hits", "essentials", "throwback", "2024", "new", "fresh", "daily", "ultimate",
+		},
+		Locales: []string{
+			"deutsch", "español", "français", "日本語", "한국어", "latino", "português", "brazil", "italiano", "हिन्दी", "العربية",
+		},
+		Artists: []seedArtist{
+			{
+				Name:    "Taylor Swift",
+				Aliases: []string{"taylor swift", "tay tay"},
+				SpotifyID: spotifyid.MustParseArtistID("06HL4z0CvFAxyc27GXpf02"),
+				TopTrackIDs: []spotifyid.TrackID{
+					spotifyid.MustParseTrackID("06AKEBrKUckW0KREUWRnvT"),
+					spotifyid.MustParseTrackID("2Cy7UlvJXf6xLTxpIi1D2n"),
+				},
+			},
+			{
+				Name:    "Bad Bunny",
+				Aliases: []string{"bad bunny", "conejo malo"},
+				SpotifyID: spotifyid.MustParseArtistID("4q3ewBCX7sLwd24euuV69X"),
+				TopTrackIDs: []spotifyid.TrackID{
+					spotifyid.MustParseTrackID("0LcJLqbBmaGUft1e9Mm8HV"),
+					spotifyid.MustParseTrackID("5CnpZV3q5BcESefcB3WJmz"),
+				},
+			},
+			{
+				Name:    "BTS",
+				Aliases: []string{"bts", "bangtan"},
+				SpotifyID: spotifyid.MustParseArtistID("3Nrfpe0tUJi4K4DXYWgMUX"),
+				TopTrackIDs: []spotifyid.TrackID{
+					spotifyid.MustParseTrackID("0e7ipj03S05BNilyu5bRzt"),
+					spotifyid.MustParseTrackID("62vpWI1CHwFy7tMIcSStl8"),
+				},
+			},
+			{
+				Name:    "Billie Eilish",
+				Aliases: []string{"billie eilish"},
+				SpotifyID: spotifyid.MustParseArtistID("6qqNVTkY8uBg9cP3Jd7DAH"),
+				TopTrackIDs: []spotifyid.TrackID{
+					spotifyid.MustParseTrackID("4RVwu0g32PAqgUiJoXsdF8"),
+					spotifyid.MustParseTrackID("2Fxmhks0bxGSBdJ92vM42m"),
+				},
+			},
+			{
+				Name:    "Drake",
+				Aliases: []string{"drake"},
+				SpotifyID: spotifyid.MustParseArtistID("3TVXtAsR1Inumwj472S9r4"),
+				TopTrackIDs: []spotifyid.TrackID{
+					spotifyid.MustParseTrackID("7KXjTSCq5nL1LoYtL7XAwS"),
+					spotifyid.MustParseTrackID("79LJU0YJXD8m1iS8q6fX3U"),
+				},
+			},
+		},
+		Tracks: []seedTrack{
+			{ID: spotifyid.MustParseTrackID("11dFghVXANMlKmJXsNCbNl"), Name: "Blinding Lights"},
+			{ID: spotifyid.MustParseTrackID("2XqCY74pdjpxwx1rsYc5Hm"), Name: "Dance The Night"},
+			{ID: spotifyid.MustParseTrackID("3ZCTVFBt2Brf31RLEnCkWJ"), Name: "Flowers"},
+			{ID: spotifyid.MustParseTrackID("0Q9ioqmbzEKx0G8Zb2m8RA"), Name: "As It Was"},
+			{ID: spotifyid.MustParseTrackID("2Fxmhks0bxGSBdJ92vM42m"), Name: "Bad Guy"},
+		},
+	}
+}
+
+func mergeSeeds(base, override *harvestSeeds) *harvestSeeds {
+	result := &harvestSeeds{}
+	result.Keywords = mergeStringSlices(base.Keywords, override.Keywords)
+	result.Genres = mergeStringSlices(base.Genres, override.Genres)
+	result.Moods = mergeStringSlices(base.Moods, override.Moods)
+	result.Meta = mergeStringSlices(base.Meta, override.Meta)
+	result.Locales = mergeStringSlices(base.Locales, override.Locales)
+	result.Artists = mergeArtists(base.Artists, override.Artists)
+	result.Tracks = mergeTracks(base.Tracks, override.Tracks)
+	return result
+}
+
+func mergeStringSlices(base, extra []string) []string {
+	seen := make(map[string]struct{})
+	out := make([]string, 0, len(base)+len(extra))
+	for _, v := range base {
+		normalized := strings.TrimSpace(v)
+		if normalized == "" {
+			continue
+		}
+		key := strings.ToLower(normalized)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, normalized)
+	}
+	for _, v := range extra {
+		normalized := strings.TrimSpace(v)
+		if normalized == "" {
+			continue
+		}
+		key := strings.ToLower(normalized)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, normalized)
+	}
+	return out
+}
+
+func mergeArtists(base, extra []seedArtist) []seedArtist {
+	merged := make(map[string]seedArtist)
+	order := make([]string, 0, len(base)+len(extra))
+	add := func(a seedArtist) {
+		key := string(a.SpotifyID)
+		if key == "" {
+			key = strings.ToLower(strings.TrimSpace(a.Name))
+		}
+		existing, ok := merged[key]
+		if !ok {
+			merged[key] = a
+			order = append(order, key)
+			return
+		}
+		existing.Aliases = mergeStringSlices(existing.Aliases, a.Aliases)
+		existing.TopTrackIDs = mergeTrackIDs(existing.TopTrackIDs, a.TopTrackIDs)
+		if existing.Name == "" {
+			existing.Name = a.Name
+		}
+		if existing.SpotifyID == "" {
+			existing.SpotifyID = a.SpotifyID
+		}
+		merged[key] = existing
+	}
+	for _, a := range base {
+		add(a)
+	}
+	for _, a := range extra {
+		add(a)
+	}
+	result := make([]seedArtist, 0, len(order))
+	for _, key := range order {
+		result = append(result, merged[key])
+	}
+	return result
+}
+
+func mergeTrackIDs(base, extra []spotifyid.TrackID) []spotifyid.TrackID {
+	seen := make(map[spotifyid.TrackID]struct{})
+	out := make([]spotifyid.TrackID, 0, len(base)+len(extra))
+	add := func(id spotifyid.TrackID) {
+		if id == "" {
+			return
+		}
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	for _, id := range base {
+		add(id)
+	}
+	for _, id := range extra {
+		add(id)
+	}
+	return out
+}
+
+func mergeTracks(base, extra []seedTrack) []seedTrack {
+	merged := make(map[string]seedTrack)
+	order := make([]string, 0, len(base)+len(extra))
+	add := func(t seedTrack) {
+		key := string(t.ID)
+		if key == "" {
+			key = strings.ToLower(strings.TrimSpace(t.Name))
+		}
+		if key == "" {
+			return
+		}
+		if _, ok := merged[key]; !ok {
+			merged[key] = t
+			order = append(order, key)
+		}
+	}
+	for _, t := range base {
+		add(t)
+	}
+	for _, t := range extra {
+		add(t)
+	}
+	result := make([]seedTrack, 0, len(order))
+	for _, key := range order {
+		result = append(result, merged[key])
+	}
+	return result
+}
+
+func generateSeedQueries(seeds *harvestSeeds) []seedQuery {
+	seen := make(map[string]struct{})
+	result := make([]seedQuery, 0, 512)
+	add := func(text, source string) {
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" {
+			return
+		}
+		key := strings.ToLower(trimmed)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		result = append(result, seedQuery{Query: trimmed, Source: source})
+	}
+
+	for _, kw := range seeds.Keywords {
+		add(kw, "keyword")
+	}
+	for _, genre := range seeds.Genres {
+		add(genre, "genre")
+	}
+	for _, mood := range seeds.Moods {
+		add(mood, "mood")
+	}
+	for _, locale := range seeds.Locales {
+		add(locale, "locale")
+	}
+	for _, meta := range seeds.Meta {
+		add(meta, "meta")
+	}
+
+	for _, mood := range seeds.Moods {
+		for _, genre := range seeds.Genres {
+			add(fmt.Sprintf("%s %s", mood, genre), "mood+genre")
+		}
+	}
+
+	for _, locale := range seeds.Locales {
+		for _, genre := range seeds.Genres {
+			add(fmt.Sprintf("%s %s", locale, genre), "locale+genre")
+		}
+	}
+
+	for _, meta := range seeds.Meta {
+		for _, genre := range seeds.Genres {
+			add(fmt.Sprintf("%s %s", meta, genre), "meta+genre")
+		}
+	}
+
+	for _, artist := range seeds.Artists {
+		add(artist.Name, "artist")
+		add(fmt.Sprintf("%s best", artist.Name), "artist-meta")
+		add(fmt.Sprintf("%s hits", artist.Name), "artist-meta")
+		for _, alias := range artist.Aliases {
+			add(alias, "artist-alias")
+			add(fmt.Sprintf("%s hits", alias), "artist-alias")
+		}
+	}
+
+	for _, track := range seeds.Tracks {
+		add(track.Name, "track")
+		add(fmt.Sprintf("%s playlist", track.Name), "track")
+	}
+
+	return result
+}
+
+func newSpotifyClient(tokens tokenProvider, rate float64) *spotifyClient {
+	if rate <= 0 {
+		rate = 5.0
+	}
+	client := resty.New()
+	client.SetBaseURL("https://api.spotify.com")
+	client.SetHeader("Accept", "application/json")
+	client.SetTimeout(30 * time.Second)
+	rl := newRateLimiter(rate)
+	c := &spotifyClient{rest: client, tokens: tokens, limiter: rl, statsDone: make(chan struct{})}
+	go c.logLimiterStats()
+	return c
+}
+
+// logLimiterStats logs the token bucket's current rate and fill level every
+// 30s (Spotify's own rolling rate-limit window), so a long-running
+// --schedule process reports whether it's being throttled without needing a
+// separate debug endpoint.
+func (c *spotifyClient) logLimiterStats() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.statsDone:
+			return
+		case <-ticker.C:
+			rate, tokens, capacity := c.limiter.Stats()
+			log.Printf("rate limiter status: rate=%.2f req/s tokens=%.1f/%.1f", rate, tokens, capacity)
+		}
+	}
+}
+
+func (c *spotifyClient) Close() {
+	if c.statsDone != nil {
+		close(c.statsDone)
+	}
+}
+
+func (c *spotifyClient) searchPlaylists(ctx context.Context, query string, offset int) (playlistPage, error) {
+	req := c.rest.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"q":      query,
+			"type":   "playlist",
+			"limit":  "50",
+			"offset": strconv.Itoa(offset),
+		})
+	resp, err := c.execute(ctx, req, http.MethodGet, "search", "/v1/search")
+	if err != nil {
+		return playlistPage{}, err
+	}
+	var payload searchResponse
+	if err := json.Unmarshal(resp.Body(), &payload); err != nil {
+		return playlistPage{}, err
+	}
+	return payload.Playlists, nil
+}
+
+func (c *spotifyClient) getPlaylist(ctx context.Context, playlistID spotifyid.PlaylistID) (*playlistDetail, error) {
+	fields := "id,name,description,public,collaborative,followers.total,owner(id,display_name),snapshot_id,images(url,height,width),tracks.total"
+	path := fmt.Sprintf("/v1/playlists/%s", url.PathEscape(playlistID.String()))
+	req := c.rest.R().
+		SetContext(ctx).
+		SetQueryParam("fields", fields)
+	resp, err := c.execute(ctx, req, http.MethodGet, "playlist", path)
+	if err != nil {
+		return nil, err
+	}
+	var detail playlistDetail
+	if err := json.Unmarshal(resp.Body(), &detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+func (c *spotifyClient) getPlaylistTracks(ctx context.Context, playlistID spotifyid.PlaylistID) ([]playlistTrackItem, error) {
+	all := make([]playlistTrackItem, 0, 128)
+	offset := 0
+	for {
+		req := c.rest.R().
+			SetContext(ctx).
+			SetQueryParams(map[string]string{
+				"limit":  "100",
+				"offset": strconv.Itoa(offset),
+				"fields": "items(added_at,added_by(id),track(id,name,uri,duration_ms,external_urls,external_ids,artists(id,name),album(id,name))),next",
+			})
+		path := fmt.Sprintf("/v1/playlists/%s/tracks", url.PathEscape(playlistID.String()))
+		resp, err := c.execute(ctx, req, http.MethodGet, "playlist_tracks", path)
+		if err != nil {
+			return nil, err
+		}
+		var page playlistTracksPage
+		if err := json.Unmarshal(resp.Body(), &page); err != nil {
+			return nil, err
+		}
+		if len(page.Items) == 0 {
+			break
+		}
+		all = append(all, page.Items...)
+		if page.Next == "" {
+			break
+		}
+		offset += len(page.Items)
+	}
+	return all, nil
+}
+
+func (c *spotifyClient) listCategories(ctx context.Context) ([]category, error) {
+	categories := make([]category, 0, 64)
+	offset := 0
+	for {
+		req := c.rest.R().
+			SetContext(ctx).
+			SetQueryParams(map[string]string{
+				"limit":  "50",
+				"offset": strconv.Itoa(offset),
+			})
+		resp, err := c.execute(ctx, req, http.MethodGet, "categories", "/v1/browse/categories")
+		if err != nil {
+			return nil, err
+		}
+		var payload categoryList
+		if err := json.Unmarshal(resp.Body(), &payload); err != nil {
+			return nil, err
+		}
+		if len(payload.Categories.Items) == 0 {
+			break
+		}
+		categories = append(categories, payload.Categories.Items...)
+		if payload.Categories.Next == "" {
+			break
+		}
+		offset += len(payload.Categories.Items)
+	}
+	return categories, nil
+}
+
+func (c *spotifyClient) categoryPlaylists(ctx context.Context, categoryID string, offset int) (playlistPage, error) {
+	req := c.rest.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"limit":  "50",
+			"offset": strconv.Itoa(offset),
+		})
+	path := fmt.Sprintf("/v1/browse/categories/%s/playlists", url.PathEscape(categoryID))
+	resp, err := c.execute(ctx, req, http.MethodGet, "category_playlists", path)
+	if err != nil {
+		return playlistPage{}, err
+	}
+	var payload playlistPageResponse
+	if err := json.Unmarshal(resp.Body(), &payload); err != nil {
+		return playlistPage{}, err
+	}
+	return payload.Playlists, nil
+}
+
+func (c *spotifyClient) featuredPlaylists(ctx context.Context, offset int) (playlistPage, error) {
+	req := c.rest.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"limit":  "50",
+			"offset": strconv.Itoa(offset),
+		})
+	resp, err := c.execute(ctx, req, http.MethodGet, "featured", "/v1/browse/featured-playlists")
+	if err != nil {
+		return playlistPage{}, err
+	}
+	var payload featuredResponse
+	if err := json.Unmarshal(resp.Body(), &payload); err != nil {
+		return playlistPage{}, err
+	}
+	return payload.Playlists, nil
+}
+
+// execute runs req against path, retrying on 429 per the token bucket's
+// Retry-After handling and, when c.tokens is a degradable auto provider, on
+// a 401/404 that suggests route needs the fallback token instead - once per
+// route, so a genuinely broken endpoint still surfaces its error rather
+// than looping forever.
+func (c *spotifyClient) execute(ctx context.Context, req *resty.Request, method, route, path string) (*resty.Response, error) {
+	for {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		token, err := c.tokens.Token(ctx, route)
+		if err != nil {
+			return nil, fmt.Errorf("obtain spotify token for %s: %w", route, err)
+		}
+		req.SetAuthToken(token)
+
+		var resp *resty.Response
+		switch method {
+		case http.MethodGet:
+			resp, err = req.Get(path)
+		default:
+			return nil, fmt.Errorf("unsupported method %s", method)
+		}
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil, err
+			}
+			return nil, err
+		}
+		if resp.StatusCode() == http.StatusTooManyRequests {
+			c.limiter.OnRateLimited()
+			wait := parseRetryAfter(resp)
+			if wait <= 0 {
+				wait = 2 * time.Second
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+		if resp.StatusCode() == http.StatusUnauthorized || resp.StatusCode() == http.StatusNotFound {
+			if d, ok := c.tokens.(degradable); ok && d.MarkDegraded(route) {
+				log.Printf("spotify %s %s (%s) failed with status %d; retrying with fallback token", method, path, route, resp.StatusCode())
+				continue
+			}
+		}
+		if resp.IsError() {
+			body := strings.TrimSpace(string(resp.Body()))
+			if len(body) > 512 {
+				body = body[:512] + "..."
+			}
+			return nil, fmt.Errorf("spotify %s %s failed with status %d: %s", method, path, resp.StatusCode(), body)
+		}
+		c.limiter.OnSuccess()
+		atomic.AddInt64(&c.requests, 1)
+		return resp, nil
+	}
+}
+
+// RequestCount returns the number of requests that have completed
+// successfully so far, for the search harvest's live QPS reporting.
+func (c *spotifyClient) RequestCount() int64 {
+	return atomic.LoadInt64(&c.requests)
+}
+
+func parseRetryAfter(resp *resty.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	if v := resp.Header().Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+		if t, err := time.Parse(time.RFC1123, v); err == nil {
+			d := time.Until(t)
+			if d < 0 {
+				return 0
+			}
+			return d
+		}
+	}
+	if v := resp.Header().Get("Retry-After-Ms"); v != "" {
+		if ms, err := strconv.Atoi(v); err == nil {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	return 0
+}
+
+func newCSVStore(path string, header []string) (*csvStore, error) {
+	dir := filepath.Dir(path)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create csv dir: %w", err)
+		}
+	}
+	_, err := os.Stat(path)
+	exists := err == nil
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open csv %s: %w", path, err)
+	}
+	writer := csv.NewWriter(file)
+	if !exists {
+		if err := writer.Write(header); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("write csv header: %w", err)
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("flush csv header: %w", err)
+		}
+	}
+	return &csvStore{file: file, writer: writer}, nil
+}
+
+func (c *csvStore) Write(record []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.writer.Write(record); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvStore) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		c.file.Close()
+		return err
+	}
+	return c.file.Close()
+}
+
+func loadSnapshotCache(path string) (*snapshotCache, error) {
+	cache := &snapshotCache{path: path, data: make(map[string]string)}
+	if path == "" {
+		return cache, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return nil, fmt.Errorf("read snapshot cache: %w", err)
+	}
+	if len(data) == 0 {
+		return cache, nil
+	}
+	if err := json.Unmarshal(data, &cache.data); err != nil {
+		return nil, fmt.Errorf("parse snapshot cache: %w", err)
+	}
+	return cache, nil
+}
+
+func (s *snapshotCache) IsUnchanged(id, snapshot string) bool {
+	if snapshot == "" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, ok := s.data[id]
+	return ok && prev == snapshot
+}
+
+func (s *snapshotCache) Existed(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.data[id]
+	return ok
+}
+
+func (s *snapshotCache) Update(id, snapshot string) {
+	if s == nil || snapshot == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.data[id]; ok && existing == snapshot {
+		return
+	}
+	s.data[id] = snapshot
+	s.dirty = true
+}
+
+func (s *snapshotCache) Save() error {
+	if s == nil || s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("ensure snapshot dir: %w", err)
+	}
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal snapshot cache: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write snapshot cache: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
+
+func newHarvester(client *spotifyClient, seeds *harvestSeeds, sinks []PlaylistSink, snapshots snapshotStore, seedRepo store.SeedRepository, metadataSource metadata.Source, enrichment *csvStore, explain *csvStore, opts harvestOptions) *harvester {
+	index := buildSeedIndex(seeds)
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = runtime.NumCPU()
+	}
+	if opts.KeywordWeight == 0 {
+		opts.KeywordWeight = 1.0
+	}
+	if opts.ArtistWeight == 0 {
+		opts.ArtistWeight = 1.5
+	}
+	if opts.TrackWeight == 0 {
+		opts.TrackWeight = 2.0
+	}
+	if opts.FollowerWeight == 0 {
+		opts.FollowerWeight = 0.5
+	}
+	if opts.FreshnessWeight == 0 {
+		opts.FreshnessWeight = 1.0
+	}
+	if opts.BM25K1 == 0 {
+		opts.BM25K1 = 1.2
+	}
+	if opts.BM25B == 0 {
+		opts.BM25B = 0.75
+	}
+	return &harvester{
+		client:     client,
+		seeds:      seeds,
+		index:      index,
+		sinks:      sinks,
+		snapshots:  snapshots,
+		seedRepo:   seedRepo,
+		metadata:   metadataSource,
+		enrichment: enrichment,
+		explain:    explain,
+		bm25:       newBM25Index(),
+		seen:       make(map[string]struct{}),
+		limiter:    rate.NewLimiter(rate.Limit(jobRate), jobBurst),
+		opts:       opts,
+	}
+}
+
+// playlistJob is one playlist ID queued for concurrent processing, tagged
+// with the harvestOrigin processPlaylist should record it under.
+type playlistJob struct {
+	id     string
+	origin harvestOrigin
+}
+
+// processPlaylistsConcurrently drains jobs across h.opts.Concurrency
+// workers. Each worker waits on the harvester's shared rate limiter before
+// calling processPlaylist, which pairs with the Retry-After handling
+// inside spotifyClient.execute to keep a burst of workers from
+// all landing on Spotify at once. Per-job errors are collected into one
+// multiErr instead of harvestFeatured/harvestCategories each keeping their
+// own []string+mutex.
+func (h *harvester) processPlaylistsConcurrently(ctx context.Context, jobs []playlistJob) error {
+	workers := h.opts.Concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+
+	workCh := make(chan playlistJob)
+	go func() {
+		defer close(workCh)
+		for _, job := range jobs {
+			select {
+			case workCh <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var errs multiErr
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range workCh {
+				if err := h.limiter.Wait(ctx); err != nil {
+					errs.Add(err)
+					continue
+				}
+				if err := h.processPlaylist(ctx, job.id, job.origin); err != nil {
+					errs.Add(fmt.Errorf("playlist %s: %w", job.id, err))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return errs.ErrorOrNil()
+}
+
+// harvestFeatured walks the featured-playlists pages sequentially, 50
+// playlists per page at offset page*50, stopping early at an empty page or
+// one with no Next link, then hands each page's playlists to
+// processPlaylistsConcurrently so they're processed across h.opts.Concurrency
+// workers instead of one at a time.
+func (h *harvester) harvestFeatured(ctx context.Context) error {
+	var errs multiErr
+	pages := h.opts.MaxBrowsePages
+	if pages <= 0 {
+		pages = 1
+	}
+	for page := 0; page < pages; page++ {
+		offset := page * 50
+		pageData, err := h.client.featuredPlaylists(ctx, offset)
+		if err != nil {
+			errs.Add(fmt.Errorf("featured offset %d: %w", offset, err))
+			break
+		}
+		if len(pageData.Items) == 0 {
+			break
+		}
+		jobs := make([]playlistJob, 0, len(pageData.Items))
+		for _, pl := range pageData.Items {
+			jobs = append(jobs, playlistJob{id: pl.ID, origin: harvestOrigin{Source: "featured", Query: "featured"}})
+		}
+		errs.Add(h.processPlaylistsConcurrently(ctx, jobs))
+		if pageData.Next == "" {
+			break
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// harvestCategories pages through every browse category sequentially for the
+// same reason harvestFeatured does, but processes each page's playlists
+// concurrently via processPlaylistsConcurrently.
+func (h *harvester) harvestCategories(ctx context.Context) error {
+	categories, err := h.client.listCategories(ctx)
+	if err != nil {
+		return err
+	}
+	var errs multiErr
+	pages := h.opts.MaxBrowsePages
+	if pages <= 0 {
+		pages = 1
+	}
+	for _, cat := range categories {
+		for page := 0; page < pages; page++ {
+			offset := page * 50
+			pageData, err := h.client.categoryPlaylists(ctx, cat.ID, offset)
+			if err != nil {
+				errs.Add(fmt.Errorf("category %s offset %d: %w", cat.ID, offset, err))
+				break
+			}
+			if len(pageData.Items) == 0 {
+				break
+			}
+			jobs := make([]playlistJob, 0, len(pageData.Items))
+			for _, pl := range pageData.Items {
+				jobs = append(jobs, playlistJob{id: pl.ID, origin: harvestOrigin{Source: fmt.Sprintf("category:%s", cat.ID), Query: cat.Name}})
+			}
+			errs.Add(h.processPlaylistsConcurrently(ctx, jobs))
+			if pageData.Next == "" {
+				break
+			}
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+func (h *harvester) processPlaylist(ctx context.Context, rawPlaylistID string, origin harvestOrigin) error {
+	if rawPlaylistID == "" {
+		return nil
+	}
+	if !h.markSeen(rawPlaylistID) {
+		return nil
+	}
+	playlistID, err := spotifyid.ParsePlaylistID(rawPlaylistID)
+	if err != nil {
+		h.stats.mu.Lock()
+		h.stats.Errors++
+		h.stats.mu.Unlock()
+		return fmt.Errorf("spotify returned malformed playlist id: %w", err)
+	}
+	h.stats.mu.Lock()
+	h.stats.Scanned++
+	h.stats.mu.Unlock()
+
+	detail, err := h.client.getPlaylist(ctx, playlistID)
+	if err != nil {
+		h.stats.mu.Lock()
+		h.stats.Errors++
+		h.stats.mu.Unlock()
+		return fmt.Errorf("get playlist: %w", err)
+	}
+	if h.snapshots != nil && h.snapshots.IsUnchanged(playlistID.String(), detail.SnapshotID) {
+		h.stats.mu.Lock()
+		h.stats.SkippedSnapshot++
+		h.stats.mu.Unlock()
+		return nil
+	}
+	tracks, err := h.client.getPlaylistTracks(ctx, playlistID)
+	if err != nil {
+		h.stats.mu.Lock()
+		h.stats.Errors++
+		h.stats.mu.Unlock()
+		return fmt.Errorf("get tracks: %w", err)
+	}
+	relevance := h.computeRelevance(detail, tracks)
+	if h.opts.Explain {
+		h.writeExplain(detail, relevance)
+	}
+	existed := h.snapshots.Existed(playlistID.String())
+	h.snapshots.Update(playlistID.String(), detail.SnapshotID)
+	if relevance.Score < h.opts.ScoreThreshold {
+		log.Printf("skip playlist %s (%s) with score %.2f", detail.Name, playlistID, relevance.Score)
+		h.stats.mu.Lock()
+		h.stats.SkippedScore++
+		h.stats.mu.Unlock()
+		return nil
+	}
+	for _, sink := range h.sinks {
+		if err := sink.WritePlaylist(detail, tracks, origin, relevance); err != nil {
+			h.stats.mu.Lock()
+			h.stats.Errors++
+			h.stats.mu.Unlock()
+			return fmt.Errorf("write playlist %s: %w", detail.ID, err)
+		}
+	}
+	h.stats.mu.Lock()
+	h.stats.Written++
+	if existed {
+		h.stats.Updated++
+	} else {
+		h.stats.New++
+	}
+	h.stats.mu.Unlock()
+
+	if h.opts.EnableMusicBrainz {
+		h.enrichTracks(ctx, tracks)
+	}
+	return nil
+}
+
+// writeExplain appends relevance's per-component breakdown for detail to
+// the explain CSV, so --explain runs can be tuned by inspecting which
+// factor drove (or failed to drive) a playlist's score.
+func (h *harvester) writeExplain(detail *playlistDetail, relevance relevanceResult) {
+	record := []string{
+		detail.ID,
+		sanitizeCSVField(detail.Name),
+		fmt.Sprintf("%.4f", relevance.Score),
+		fmt.Sprintf("%.4f", relevance.Components["keyword_bm25"]),
+		fmt.Sprintf("%.4f", relevance.Components["artist"]),
+		fmt.Sprintf("%.4f", relevance.Components["track"]),
+		fmt.Sprintf("%.4f", relevance.Components["follower"]),
+		fmt.Sprintf("%.4f", relevance.Components["freshness"]),
+	}
+	if err := h.explain.Write(record); err != nil {
+		log.Printf("write playlist_score_explain row for %s: %v", detail.ID, err)
+	}
+}
+
+// enrichTracks resolves each track against MusicBrainz and appends any hit
+// to the track_enrichment CSV. It's best-effort: a failed lookup is logged
+// and skipped, and a track with no match is skipped, rather than failing the playlist write,
+// since enrichment is a bonus pass over data that's already persisted.
+func (h *harvester) enrichTracks(ctx context.Context, tracks []playlistTrackItem) {
+	for _, item := range tracks {
+		if item.Track.ID == "" {
+			continue
+		}
+		artists := make([]string, 0, len(item.Track.Artists))
+		for _, a := range item.Track.Artists {
+			artists = append(artists, a.Name)
+		}
+		query := metadata.TrackQuery{
+			ISRC:     item.Track.ExternalIDs.ISRC,
+			Title:    item.Track.Name,
+			Artist:   strings.Join(artists, " "),
+			Duration: time.Duration(item.Track.DurationMs) * time.Millisecond,
+		}
+		result, err := h.metadata.Resolve(ctx, query)
+		if err != nil {
+			log.Printf("musicbrainz lookup for track %s failed: %v", item.Track.ID, err)
+			continue
+		}
+		if result == nil {
+			continue
+		}
+		record := []string{
+			item.Track.ID,
+			result.ISRC,
+			result.ArtistMBID,
+			result.ReleaseGroupID,
+			result.OriginalReleaseDate,
+			result.Label,
+			strings.Join(result.Genres, "|"),
+			result.MatchMethod,
+			fmt.Sprintf("%.2f", result.MatchScore),
+		}
+		if err := h.enrichment.Write(record); err != nil {
+			log.Printf("write track_enrichment row for %s: %v", item.Track.ID, err)
+		}
+	}
+}
+
+// markSeen reports whether id is new (true) - i.e. this is the first
+// process-playlist worker to claim it - mirroring the
+// true-means-new convention of crawler.SeenStore.MarkSeen. It returns false
+// for an id already recorded, so a concurrent caller knows to skip it.
+func (h *harvester) markSeen(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.seen[id]; ok {
+		return false
+	}
+	h.seen[id] = struct{}{}
+	return true
+}
+
+func buildSeedIndex(seeds *harvestSeeds) *seedIndex {
+	if seeds == nil {
+		seeds = defaultHarvestSeeds()
+	}
+	idx := &seedIndex{
+		keywords:     make([]string, 0, len(seeds.Keywords)+len(seeds.Genres)+len(seeds.Moods)+len(seeds.Meta)+len(seeds.Locales)+len(seeds.Tracks)),
+		artistByName: make(map[string]seedArtist),
+		artistByID:   make(map[string]seedArtist),
+		trackByID:    make(map[string]seedTrack),
+	}
+	addKeyword := func(value string) {
+		v := strings.TrimSpace(strings.ToLower(value))
+		if v == "" {
+			return
+		}
+		idx.keywords = append(idx.keywords, v)
+	}
+	lists := [][]string{seeds.Keywords, seeds.Genres, seeds.Moods, seeds.Meta, seeds.Locales}
+	for _, list := range lists {
+		for _, item := range list {
+			addKeyword(item)
+		}
+	}
+	for _, track := range seeds.Tracks {
+		if track.ID != "" {
+			idx.trackByID[string(track.ID)] = track
+		}
+		addKeyword(track.Name)
+	}
+	for _, artist := range seeds.Artists {
+		if artist.SpotifyID != "" {
+			idx.artistByID[strings.ToLower(string(artist.SpotifyID))] = artist
+		}
+		addKeyword(artist.Name)
+		idx.artistByName[strings.ToLower(artist.Name)] = artist
+		for _, alias := range artist.Aliases {
+			idx.artistByName[strings.ToLower(alias)] = artist
+			addKeyword(alias)
+		}
+	}
+	return idx
+}
+
+func setToSortedSlice(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(set))
+	for v := range set {
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sanitizeCSVField(s string) string {
+	replaced := strings.ReplaceAll(s, "\r\n", " ")
+	replaced = strings.ReplaceAll(replaced, "\n", " ")
+	replaced = strings.ReplaceAll(replaced, "\r", " ")
+	return strings.TrimSpace(replaced)
+}
+
+func selectImageURL(images []struct {
+	URL    string `json:"url"`
+	Height int    `json:"height"`
+	Width  int    `json:"width"`
+}) string {
+	if len(images) == 0 {
+		return ""
+	}
+	return images[0].URL
+}
+
+type clientCredentialsResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// requestClientCredentialsToken returns an access token good for expiresIn
+// seconds via Spotify's Client Credentials Flow.
+func requestClientCredentialsToken(clientID, clientSecret string) (token string, expiresIn int, err error) {
+	client := resty.New()
+	resp, err := client.R().
+		SetBasicAuth(clientID, clientSecret).
+		SetHeader("Content-Type", "application/x-www-form-urlencoded").
+		SetBody("grant_type=client_credentials").
+		Post("https://accounts.spotify.com/api/token")
+	if err != nil {
+		return "", 0, err
+	}
+	if resp.IsError() {
+		return "", 0, fmt.Errorf("token request failed with status %d", resp.StatusCode())
+	}
+	var payload clientCredentialsResponse
+	if err := json.Unmarshal(resp.Body(), &payload); err != nil {
+		return "", 0, err
+	}
+	return payload.AccessToken, payload.ExpiresIn, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// jsonlSink appends one JSON object per harvested playlist (with its full
+// track listing inline) to <dir>/playlists.jsonl, one line per record. This
+// is the cheapest format to stream into a downstream pipeline that wants
+// the whole playlist+tracks shape without a SQL join or a CSV schema.
+type jsonlSink struct {
+	dir  string
+	mu   sync.Mutex
+	file *os.File
+}
+
+type jsonlRecord struct {
+	Playlist  *playlistDetail     `json:"playlist"`
+	Tracks    []playlistTrackItem `json:"tracks"`
+	Origin    harvestOrigin       `json:"origin"`
+	Relevance relevanceResult     `json:"relevance"`
+}
+
+func (s *jsonlSink) open() (*os.File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file != nil {
+		return s.file, nil
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create jsonl dir: %w", err)
+	}
+	path := filepath.Join(s.dir, "playlists.jsonl")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	s.file = file
+	return file, nil
+}
+
+func (s *jsonlSink) WritePlaylist(detail *playlistDetail, tracks []playlistTrackItem, origin harvestOrigin, relevance relevanceResult) error {
+	file, err := s.open()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(jsonlRecord{Playlist: detail, Tracks: tracks, Origin: origin, Relevance: relevance})
+	if err != nil {
+		return fmt.Errorf("marshal jsonl record for %s: %w", detail.ID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write jsonl record for %s: %w", detail.ID, err)
+	}
+	return nil
+}
+
+func (s *jsonlSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
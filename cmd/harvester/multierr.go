@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// multiErr accumulates errors reported by independent goroutines behind a
+// mutex and folds them into a single error, replacing the ad hoc
+// []string+sync.Mutex pattern previously duplicated across
+// harvestSearch/harvestFeatured/harvestCategories.
+type multiErr struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// Add records err, ignoring nil so callers can pass a fallible call's
+// return value straight through.
+func (m *multiErr) Add(err error) {
+	if err == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errs = append(m.errs, err)
+}
+
+// ErrorOrNil returns nil if nothing was ever added, the single recorded
+// error if there was exactly one, or a combined error listing every
+// message otherwise.
+func (m *multiErr) ErrorOrNil() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch len(m.errs) {
+	case 0:
+		return nil
+	case 1:
+		return m.errs[0]
+	default:
+		msgs := make([]string, len(m.errs))
+		for i, err := range m.errs {
+			msgs[i] = err.Error()
+		}
+		return fmt.Errorf("%d errors: %s", len(m.errs), strings.Join(msgs, "; "))
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+func TestNewRateLimiterDefaultsNonPositiveRate(t *testing.T) {
+	r := newRateLimiter(0)
+	rate, tokens, capacity := r.Stats()
+	if rate != 5.0 {
+		t.Errorf("newRateLimiter(0) rate = %v, want 5.0", rate)
+	}
+	if capacity != 5.0 {
+		t.Errorf("newRateLimiter(0) capacity = %v, want 5.0", capacity)
+	}
+	if tokens != capacity {
+		t.Errorf("newRateLimiter starts with tokens = %v, want full capacity %v", tokens, capacity)
+	}
+}
+
+func TestRateLimiterOnRateLimitedHalvesRateAndDrainsTokens(t *testing.T) {
+	r := newRateLimiter(8)
+	r.OnRateLimited()
+	rate, tokens, _ := r.Stats()
+	if rate != 4 {
+		t.Errorf("rate after one OnRateLimited = %v, want 4 (halved)", rate)
+	}
+	if tokens > 0.1 {
+		t.Errorf("tokens after OnRateLimited = %v, want ~0 (drained)", tokens)
+	}
+}
+
+func TestRateLimiterOnRateLimitedFloorsAtMinRate(t *testing.T) {
+	r := newRateLimiter(8) // minRate = 8/8 = 1
+	for i := 0; i < 10; i++ {
+		r.OnRateLimited()
+	}
+	rate, _, _ := r.Stats()
+	if rate != 1 {
+		t.Errorf("rate after repeated backoff = %v, want floored at minRate 1", rate)
+	}
+}
+
+func TestRateLimiterOnSuccessRequiresConsecutiveSuccessesBeforeRecovering(t *testing.T) {
+	r := newRateLimiter(8)
+	r.OnRateLimited() // rate now 4
+
+	for i := 0; i < rateRecoverySuccesses-1; i++ {
+		r.OnSuccess()
+	}
+	rate, _, _ := r.Stats()
+	if rate != 4 {
+		t.Errorf("rate after %d successes = %v, want still 4 (not yet recovered)", rateRecoverySuccesses-1, rate)
+	}
+
+	r.OnSuccess() // the rateRecoverySuccesses-th success
+	rate, _, _ = r.Stats()
+	if rate <= 4 {
+		t.Errorf("rate after %d successes = %v, want increased above 4", rateRecoverySuccesses, rate)
+	}
+}
+
+func TestRateLimiterOnSuccessDoesNotExceedBaseRate(t *testing.T) {
+	r := newRateLimiter(8)
+	if rate, _, _ := r.Stats(); rate != 8 {
+		t.Fatalf("newRateLimiter(8) rate = %v, want 8", rate)
+	}
+	r.OnSuccess() // already at baseRate, should be a no-op
+	rate, _, _ := r.Stats()
+	if rate != 8 {
+		t.Errorf("OnSuccess at baseRate changed rate to %v, want unchanged 8", rate)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bm25Index tracks the running corpus statistics computeRelevance's BM25
+// keyword score is computed against: how many documents (playlists) have
+// been scored so far, their average length, and how many contained each
+// seed keyword. It's updated after every playlist is scored, so avgdl and
+// each keyword's IDF drift towards the actual corpus as a harvest run
+// progresses, rather than requiring a separate indexing pass up front.
+type bm25Index struct {
+	mu       sync.Mutex
+	docCount int
+	totalLen float64
+	docFreq  map[string]int
+}
+
+func newBM25Index() *bm25Index {
+	return &bm25Index{docFreq: make(map[string]int)}
+}
+
+// avgdl returns the running average document length, defaulting to 1 so
+// the BM25 denominator is well-defined before any playlist is observed.
+func (b *bm25Index) avgdl() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.docCount == 0 {
+		return 1
+	}
+	return b.totalLen / float64(b.docCount)
+}
+
+// idf returns the Robertson-Sparck Jones IDF for keyword given the current
+// docCount/docFreq snapshot, floored at 0 so a keyword present in most of
+// the corpus can't drive the score negative.
+func (b *bm25Index) idf(keyword string) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := float64(b.docCount)
+	df := float64(b.docFreq[keyword])
+	v := math.Log(1 + (n-df+0.5)/(df+0.5))
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+// observe records dl (the scored document's length) and which keywords it
+// contained, so the next call to avgdl/idf reflects this document too.
+func (b *bm25Index) observe(dl int, present map[string]struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.docCount++
+	b.totalLen += float64(dl)
+	for kw := range present {
+		b.docFreq[kw]++
+	}
+}
+
+// computeRelevance scores detail+tracks against h.index using a BM25
+// keyword score plus the same artist/track/follower/freshness bonuses the
+// original flat scorer used, each independently weighted via h.opts. The
+// per-factor contributions are exposed via relevanceResult.Components so
+// --explain can report exactly what drove a playlist's score.
+func (h *harvester) computeRelevance(detail *playlistDetail, tracks []playlistTrackItem) relevanceResult {
+	idx := h.index
+	if idx == nil {
+		idx = buildSeedIndex(defaultHarvestSeeds())
+	}
+	var result relevanceResult
+	if detail == nil {
+		return result
+	}
+
+	trackNames := make([]string, 0, len(tracks))
+	for _, item := range tracks {
+		if item.Track.Name != "" {
+			trackNames = append(trackNames, item.Track.Name)
+		}
+	}
+	text := strings.ToLower(strings.Join([]string{detail.Name, detail.Description, detail.Owner.DisplayName, strings.Join(trackNames, " ")}, " "))
+	dl := len(strings.Fields(text))
+
+	k1, b := h.opts.BM25K1, h.opts.BM25B
+	avgdl := h.bm25.avgdl()
+
+	keywordMatches := make(map[string]struct{})
+	present := make(map[string]struct{})
+	var keywordScore float64
+	for _, keyword := range idx.keywords {
+		if keyword == "" {
+			continue
+		}
+		tf := float64(strings.Count(text, keyword))
+		if tf == 0 {
+			continue
+		}
+		keywordMatches[keyword] = struct{}{}
+		present[keyword] = struct{}{}
+		keywordScore += h.bm25.idf(keyword) * (tf * (k1 + 1)) / (tf + k1*(1-b+b*float64(dl)/avgdl))
+	}
+	h.bm25.observe(dl, present)
+	keywordScore *= h.opts.KeywordWeight
+
+	artistMatches := make(map[string]struct{})
+	trackMatches := make(map[string]struct{})
+	var latest time.Time
+
+	for _, item := range tracks {
+		if item.AddedAt != "" {
+			if t, err := time.Parse(time.RFC3339, item.AddedAt); err == nil {
+				if t.After(latest) {
+					latest = t
+				}
+			}
+		}
+		trackID := item.Track.ID
+		if trackID != "" {
+			if track, ok := idx.trackByID[trackID]; ok {
+				trackMatches[track.Name] = struct{}{}
+			}
+		}
+		for _, artist := range item.Track.Artists {
+			if artist.ID != "" {
+				if seedArtist, ok := idx.artistByID[strings.ToLower(artist.ID)]; ok {
+					artistMatches[seedArtist.Name] = struct{}{}
+				}
+			}
+			if seedArtist, ok := idx.artistByName[strings.ToLower(artist.Name)]; ok {
+				artistMatches[seedArtist.Name] = struct{}{}
+			}
+		}
+	}
+
+	keywordList := setToSortedSlice(keywordMatches)
+	artistList := setToSortedSlice(artistMatches)
+	trackList := setToSortedSlice(trackMatches)
+
+	followerBoost := math.Log10(float64(detail.Followers.Total) + 1)
+	freshnessBoost := 0.0
+	freshnessDays := -1
+	if !latest.IsZero() {
+		days := time.Since(latest).Hours() / 24
+		if days < 0 {
+			days = 0
+		}
+		freshnessDays = int(math.Round(days))
+		if days < 30 {
+			freshnessBoost = (30 - days) / 30 * 2.0
+		}
+	}
+
+	artistScore := float64(len(artistList)) * h.opts.ArtistWeight
+	trackScore := float64(len(trackList)) * h.opts.TrackWeight
+	followerScore := followerBoost * h.opts.FollowerWeight
+	freshnessScore := freshnessBoost * h.opts.FreshnessWeight
+
+	result.Score = keywordScore + artistScore + trackScore + followerScore + freshnessScore
+	result.KeywordMatches = keywordList
+	result.ArtistMatches = artistList
+	result.TrackMatches = trackList
+	result.FollowerBoost = followerBoost
+	result.FreshnessBoost = freshnessBoost
+	result.FreshnessDays = freshnessDays
+	result.Components = map[string]float64{
+		"keyword_bm25": keywordScore,
+		"artist":       artistScore,
+		"track":        trackScore,
+		"follower":     followerScore,
+		"freshness":    freshnessScore,
+	}
+	return result
+}
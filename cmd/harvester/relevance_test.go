@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestBM25IndexAvgdlDefaultsToOneBeforeAnyObservation(t *testing.T) {
+	idx := newBM25Index()
+	if got := idx.avgdl(); got != 1 {
+		t.Errorf("avgdl() on empty index = %v, want 1", got)
+	}
+}
+
+func TestBM25IndexAvgdlTracksRunningAverage(t *testing.T) {
+	idx := newBM25Index()
+	idx.observe(10, nil)
+	idx.observe(20, nil)
+	if got, want := idx.avgdl(), 15.0; got != want {
+		t.Errorf("avgdl() after observing 10,20 = %v, want %v", got, want)
+	}
+}
+
+func TestBM25IndexIDFDecreasesAsDocFreqRises(t *testing.T) {
+	idx := newBM25Index()
+	idx.observe(5, map[string]struct{}{"rock": {}})
+	idx.observe(5, map[string]struct{}{})
+	idx.observe(5, map[string]struct{}{})
+
+	rareIDF := idx.idf("rock")
+
+	idx.observe(5, map[string]struct{}{"rock": {}})
+	idx.observe(5, map[string]struct{}{"rock": {}})
+	idx.observe(5, map[string]struct{}{"rock": {}})
+	idx.observe(5, map[string]struct{}{"rock": {}})
+	idx.observe(5, map[string]struct{}{"rock": {}})
+	commonIDF := idx.idf("rock")
+
+	if commonIDF >= rareIDF {
+		t.Errorf("idf(%q) = %v after becoming common, want less than its rare-case value %v", "rock", commonIDF, rareIDF)
+	}
+}
+
+func TestBM25IndexIDFFlooredAtZero(t *testing.T) {
+	idx := newBM25Index()
+	for i := 0; i < 10; i++ {
+		idx.observe(5, map[string]struct{}{"pop": {}})
+	}
+	if got := idx.idf("pop"); got < 0 {
+		t.Errorf("idf(%q) = %v for a near-universal keyword, want >= 0", "pop", got)
+	}
+}
+
+func TestBM25IndexIDFUnseenKeywordIsPositive(t *testing.T) {
+	idx := newBM25Index()
+	idx.observe(5, map[string]struct{}{"rock": {}})
+	if got := idx.idf("unseen"); got <= 0 {
+		t.Errorf("idf(%q) for a keyword never observed = %v, want > 0", "unseen", got)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// cronField is the parsed form of one of the five crontab fields: either
+// "every tick matches" (*), or an explicit set of allowed values.
+type cronField struct {
+	any    bool
+	values map[int]struct{}
+}
+
+// cronSchedule is a minimal standard 5-field crontab expression parser
+// (minute hour day-of-month month day-of-week), supporting "*", "*/N",
+// comma lists and "a-b" ranges - enough for the periodic-resync schedules
+// this harvester needs without pulling in a scheduling library.
+type cronSchedule struct {
+	minute, hour, dom, month, dow cronField
+}
+
+func parseCronSchedule(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields (minute hour dom month dow), got %d", expr, len(fields))
+	}
+	ranges := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	parsed := make([]cronField, 5)
+	for i, f := range fields {
+		cf, err := parseCronField(f, ranges[i][0], ranges[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("field %d (%q): %w", i, f, err)
+		}
+		parsed[i] = cf
+	}
+	return &cronSchedule{minute: parsed[0], hour: parsed[1], dom: parsed[2], month: parsed[3], dow: parsed[4]}, nil
+}
+
+func parseCronField(field string, min, max int) (cronField, error) {
+	if field == "*" {
+		return cronField{any: true}, nil
+	}
+
+	values := make(map[int]struct{})
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		rangePart := part
+		if idx := strings.Index(part, "/"); idx != -1 {
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil {
+				return cronField{}, fmt.Errorf("invalid step %q", part)
+			}
+			step = s
+			rangePart = part[:idx]
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			if dash := strings.Index(rangePart, "-"); dash != -1 {
+				l, err := strconv.Atoi(rangePart[:dash])
+				if err != nil {
+					return cronField{}, fmt.Errorf("invalid range start %q", rangePart)
+				}
+				h, err := strconv.Atoi(rangePart[dash+1:])
+				if err != nil {
+					return cronField{}, fmt.Errorf("invalid range end %q", rangePart)
+				}
+				lo, hi = l, h
+			} else {
+				v, err := strconv.Atoi(rangePart)
+				if err != nil {
+					return cronField{}, fmt.Errorf("invalid value %q", rangePart)
+				}
+				lo, hi = v, v
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			values[v] = struct{}{}
+		}
+	}
+	return cronField{values: values}, nil
+}
+
+func (f cronField) matches(v int) bool {
+	if f.any {
+		return true
+	}
+	_, ok := f.values[v]
+	return ok
+}
+
+// Next returns the first minute-aligned time strictly after 'after' that
+// matches the schedule, searching up to two years ahead before giving up.
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(2, 0, 0)
+	for t.Before(limit) {
+		if s.minute.matches(t.Minute()) && s.hour.matches(t.Hour()) &&
+			s.dom.matches(t.Day()) && s.month.matches(int(t.Month())) &&
+			s.dow.matches(int(t.Weekday())) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+// runScheduled invokes runOnce immediately, then again every time the cron
+// expression fires, until ctx is cancelled or SIGINT/SIGTERM is received.
+// onShutdown runs once before returning, so callers can flush caches.
+func runScheduled(ctx context.Context, expr string, runOnce func(context.Context), onShutdown func()) error {
+	schedule, err := parseCronSchedule(expr)
+	if err != nil {
+		return fmt.Errorf("parse schedule: %w", err)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	log.Printf("scheduler starting with expression %q", expr)
+	runOnce(ctx)
+
+	for {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("schedule %q never fires", expr)
+		}
+		wait := time.Until(next)
+		log.Printf("next scheduled run at %s (in %s)", next.Format(time.RFC3339), wait.Round(time.Second))
+
+		select {
+		case <-ctx.Done():
+			onShutdown()
+			return ctx.Err()
+		case sig := <-sigCh:
+			log.Printf("received %s, shutting down", sig)
+			onShutdown()
+			return nil
+		case <-time.After(wait):
+			runOnce(ctx)
+		}
+	}
+}
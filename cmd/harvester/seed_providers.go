@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// SeedProvider supplies additional harvestSeeds pulled from a real listening
+// history or library, rather than the hand-curated defaultHarvestSeeds.
+// Providers only ever contribute names (artist/track/keyword) - they don't
+// know a Spotify ID for what they found, so seedArtist.SpotifyID and
+// seedTrack.ID are left empty and mergeArtists/mergeTracks fall back to
+// matching by name.
+type SeedProvider interface {
+	FetchSeeds(ctx context.Context) (*harvestSeeds, error)
+}
+
+// parseSeedProviders parses a comma-separated --seed-provider value such as
+// "listenbrainz:user=foo,lastfm:tag=shoegaze,m3u:./mylibrary.m3u8" into one
+// provider per entry.
+func parseSeedProviders(spec string) ([]SeedProvider, error) {
+	var providers []SeedProvider
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		provider, err := parseSeedProviderSpec(entry)
+		if err != nil {
+			return nil, fmt.Errorf("seed provider %q: %w", entry, err)
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+func parseSeedProviderSpec(entry string) (SeedProvider, error) {
+	name, arg, _ := strings.Cut(entry, ":")
+	switch strings.TrimSpace(name) {
+	case "listenbrainz":
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key != "user" || value == "" {
+			return nil, fmt.Errorf("want listenbrainz:user=<username>")
+		}
+		return &listenBrainzProvider{user: value}, nil
+	case "lastfm":
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("want lastfm:user=<username> or lastfm:tag=<tag>")
+		}
+		switch key {
+		case "user":
+			return &lastFMProvider{user: value}, nil
+		case "tag":
+			return &lastFMProvider{tag: value}, nil
+		default:
+			return nil, fmt.Errorf("want lastfm:user=<username> or lastfm:tag=<tag>")
+		}
+	case "m3u":
+		if arg == "" {
+			return nil, fmt.Errorf("want m3u:<path to .m3u/.m3u8 file>")
+		}
+		return &m3uSeedProvider{path: arg}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q (want listenbrainz, lastfm or m3u)", name)
+	}
+}
+
+// listenBrainzProvider pulls a user's top recordings from the ListenBrainz
+// stats API and turns each (track, artist) pair into a seed.
+type listenBrainzProvider struct {
+	user string
+}
+
+type listenBrainzStatsResponse struct {
+	Payload struct {
+		Recordings []struct {
+			TrackName  string `json:"track_name"`
+			ArtistName string `json:"artist_name"`
+		} `json:"recordings"`
+	} `json:"payload"`
+}
+
+func (p *listenBrainzProvider) FetchSeeds(ctx context.Context) (*harvestSeeds, error) {
+	client := resty.New().SetTimeout(30 * time.Second)
+	path := fmt.Sprintf("https://api.listenbrainz.org/1/stats/user/%s/recordings", p.user)
+	resp, err := client.R().
+		SetContext(ctx).
+		SetQueryParam("count", "100").
+		SetResult(&listenBrainzStatsResponse{}).
+		Get(path)
+	if err != nil {
+		return nil, fmt.Errorf("listenbrainz stats for %s: %w", p.user, err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("listenbrainz stats for %s: status %d", p.user, resp.StatusCode())
+	}
+	payload := resp.Result().(*listenBrainzStatsResponse)
+
+	seeds := &harvestSeeds{}
+	artistSeen := make(map[string]struct{})
+	for _, rec := range payload.Payload.Recordings {
+		if rec.TrackName != "" {
+			seeds.Tracks = append(seeds.Tracks, seedTrack{Name: rec.TrackName})
+		}
+		key := strings.ToLower(rec.ArtistName)
+		if rec.ArtistName == "" || contains(artistSeen, key) {
+			continue
+		}
+		artistSeen[key] = struct{}{}
+		seeds.Artists = append(seeds.Artists, seedArtist{Name: rec.ArtistName})
+	}
+	return seeds, nil
+}
+
+// lastFMProvider pulls top artists either for a user's library or for a
+// genre tag from the Last.fm API. Last.fm requires an API key; it's read
+// from LASTFM_API_KEY, the same pattern as CLIENT_ID/CLIENT_SECRET.
+type lastFMProvider struct {
+	user string
+	tag  string
+}
+
+type lastFMTopArtistsResponse struct {
+	TopArtists struct {
+		Artist []struct {
+			Name string `json:"name"`
+		} `json:"artist"`
+	} `json:"topartists"`
+}
+
+func (p *lastFMProvider) FetchSeeds(ctx context.Context) (*harvestSeeds, error) {
+	apiKey := strings.TrimSpace(os.Getenv("LASTFM_API_KEY"))
+	if apiKey == "" {
+		return nil, fmt.Errorf("LASTFM_API_KEY must be set to use a lastfm seed provider")
+	}
+
+	client := resty.New().SetTimeout(30 * time.Second)
+	req := client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"api_key": apiKey,
+			"format":  "json",
+			"limit":   "50",
+		})
+
+	var artistNames []string
+	switch {
+	case p.user != "":
+		req.SetQueryParams(map[string]string{"method": "user.gettopartists", "user": p.user})
+		result := &lastFMTopArtistsResponse{}
+		resp, err := req.SetResult(result).Get("https://ws.audioscrobbler.com/2.0/")
+		if err != nil {
+			return nil, fmt.Errorf("lastfm top artists for user %s: %w", p.user, err)
+		}
+		if resp.IsError() {
+			return nil, fmt.Errorf("lastfm top artists for user %s: status %d", p.user, resp.StatusCode())
+		}
+		for _, a := range result.TopArtists.Artist {
+			artistNames = append(artistNames, a.Name)
+		}
+	case p.tag != "":
+		req.SetQueryParams(map[string]string{"method": "tag.gettopartists", "tag": p.tag})
+		result := &lastFMTopArtistsResponse{}
+		resp, err := req.SetResult(result).Get("https://ws.audioscrobbler.com/2.0/")
+		if err != nil {
+			return nil, fmt.Errorf("lastfm top artists for tag %s: %w", p.tag, err)
+		}
+		if resp.IsError() {
+			return nil, fmt.Errorf("lastfm top artists for tag %s: status %d", p.tag, resp.StatusCode())
+		}
+		for _, a := range result.TopArtists.Artist {
+			artistNames = append(artistNames, a.Name)
+		}
+	default:
+		return nil, fmt.Errorf("lastfm seed provider needs a user or a tag")
+	}
+
+	seeds := &harvestSeeds{}
+	for _, name := range artistNames {
+		if name == "" {
+			continue
+		}
+		seeds.Artists = append(seeds.Artists, seedArtist{Name: name})
+	}
+	if p.tag != "" {
+		seeds.Genres = append(seeds.Genres, p.tag)
+	}
+	return seeds, nil
+}
+
+// m3uSeedProvider parses #EXTINF lines ("#EXTINF:-1,Artist - Title") out of
+// a local .m3u/.m3u8 file and turns each into an artist/track seed pair.
+type m3uSeedProvider struct {
+	path string
+}
+
+func (p *m3uSeedProvider) FetchSeeds(ctx context.Context) (*harvestSeeds, error) {
+	file, err := os.Open(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("open m3u file %s: %w", p.path, err)
+	}
+	defer file.Close()
+
+	seeds := &harvestSeeds{}
+	artistSeen := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "#EXTINF:") {
+			continue
+		}
+		_, rest, ok := strings.Cut(line, ",")
+		if !ok {
+			continue
+		}
+		artist, title, ok := strings.Cut(rest, " - ")
+		if !ok {
+			continue
+		}
+		artist, title = strings.TrimSpace(artist), strings.TrimSpace(title)
+		if title != "" {
+			seeds.Tracks = append(seeds.Tracks, seedTrack{Name: title})
+		}
+		key := strings.ToLower(artist)
+		if artist == "" || contains(artistSeen, key) {
+			continue
+		}
+		artistSeen[key] = struct{}{}
+		seeds.Artists = append(seeds.Artists, seedArtist{Name: artist})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read m3u file %s: %w", p.path, err)
+	}
+	return seeds, nil
+}
+
+func contains(set map[string]struct{}, key string) bool {
+	_, ok := set[key]
+	return ok
+}
+
+// loadProviderSeeds runs every configured provider and folds its seeds into
+// base, one at a time, so a single provider failing doesn't take down the
+// others; failures are logged and skipped.
+func loadProviderSeeds(ctx context.Context, base *harvestSeeds, providers []SeedProvider) *harvestSeeds {
+	merged := base
+	for _, provider := range providers {
+		extra, err := provider.FetchSeeds(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "seed provider failed, skipping: %v\n", err)
+			continue
+		}
+		merged = mergeSeeds(merged, extra)
+	}
+	return merged
+}
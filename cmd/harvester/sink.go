@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PlaylistSink receives one fully-resolved playlist (detail + its tracks) at
+// a time and persists it however it sees fit. This lets the harvester
+// populate several output formats from a single pass instead of writing CSV
+// rows inline in processPlaylist.
+type PlaylistSink interface {
+	WritePlaylist(detail *playlistDetail, tracks []playlistTrackItem, origin harvestOrigin, relevance relevanceResult) error
+	Close() error
+}
+
+// newSinks builds one PlaylistSink per comma-separated format in
+// --export-format (csv,m3u8,jspf,jsonl) rooted at outDir.
+func newSinks(formats string, outDir string, playlistCSV, trackCSV *csvStore) ([]PlaylistSink, error) {
+	var sinks []PlaylistSink
+	for _, format := range strings.Split(formats, ",") {
+		switch strings.TrimSpace(format) {
+		case "csv":
+			sinks = append(sinks, &csvSink{playlists: playlistCSV, tracks: trackCSV})
+		case "m3u8":
+			sinks = append(sinks, &m3u8Sink{dir: filepath.Join(outDir, "m3u8")})
+		case "jspf":
+			sinks = append(sinks, &jspfSink{dir: filepath.Join(outDir, "jspf")})
+		case "jsonl":
+			sinks = append(sinks, &jsonlSink{dir: filepath.Join(outDir, "jsonl")})
+		case "":
+			// allow trailing commas in the flag value
+		default:
+			return nil, fmt.Errorf("unknown export format %q (want csv, m3u8, jspf or jsonl)", format)
+		}
+	}
+	return sinks, nil
+}
+
+// csvSink is the pre-existing CSV persistence, adapted to the PlaylistSink
+// interface so it can run alongside the new export formats.
+type csvSink struct {
+	playlists *csvStore
+	tracks    *csvStore
+}
+
+func (s *csvSink) WritePlaylist(detail *playlistDetail, tracks []playlistTrackItem, origin harvestOrigin, relevance relevanceResult) error {
+	if err := writePlaylistRecord(s.playlists, detail, origin, relevance); err != nil {
+		return err
+	}
+	return writeTrackRecords(s.tracks, detail.ID, tracks, origin)
+}
+
+func (s *csvSink) Close() error { return nil }
+
+// m3u8Sink writes one standard M3U8 file per playlist, named
+// <dir>/<playlist_id>.m3u8, so harvested playlists can be dropped straight
+// into a media server like Navidrome without a conversion step.
+type m3u8Sink struct {
+	dir string
+}
+
+func (s *m3u8Sink) WritePlaylist(detail *playlistDetail, tracks []playlistTrackItem, origin harvestOrigin, relevance relevanceResult) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("create m3u8 dir: %w", err)
+	}
+	path := filepath.Join(s.dir, detail.ID+".m3u8")
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintln(file, "#EXTM3U"); err != nil {
+		return err
+	}
+	for _, item := range tracks {
+		if item.Track.ID == "" {
+			continue
+		}
+		artists := make([]string, 0, len(item.Track.Artists))
+		for _, a := range item.Track.Artists {
+			artists = append(artists, a.Name)
+		}
+		// EXTINF carries the track length in whole seconds, or -1 ("unknown
+		// length") when Spotify reported no duration_ms for the track.
+		duration := -1
+		if item.Track.DurationMs > 0 {
+			duration = item.Track.DurationMs / 1000
+		}
+		if _, err := fmt.Fprintf(file, "#EXTINF:%d,%s - %s\n", duration, strings.Join(artists, ", "), item.Track.Name); err != nil {
+			return err
+		}
+		location := item.Track.ExternalUrls["spotify"]
+		if location == "" {
+			location = item.Track.URI
+		}
+		if _, err := fmt.Fprintln(file, location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *m3u8Sink) Close() error { return nil }
+
+// jspfSink writes one JSPF (JSON Playlist Format) file per playlist, named
+// <dir>/<playlist_id>.jspf.
+type jspfSink struct {
+	dir string
+}
+
+type jspfDocument struct {
+	Playlist jspfPlaylist `json:"playlist"`
+}
+
+type jspfPlaylist struct {
+	Title      string      `json:"title"`
+	Creator    string      `json:"creator,omitempty"`
+	Annotation string      `json:"annotation,omitempty"`
+	Image      string      `json:"image,omitempty"`
+	Track      []jspfTrack `json:"track"`
+}
+
+type jspfTrack struct {
+	Title      string   `json:"title"`
+	Creator    string   `json:"creator,omitempty"`
+	Location   []string `json:"location,omitempty"`
+	Identifier []string `json:"identifier,omitempty"`
+	Duration   int      `json:"duration,omitempty"`
+}
+
+func (s *jspfSink) WritePlaylist(detail *playlistDetail, tracks []playlistTrackItem, origin harvestOrigin, relevance relevanceResult) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("create jspf dir: %w", err)
+	}
+
+	doc := jspfDocument{Playlist: jspfPlaylist{
+		Title:      detail.Name,
+		Creator:    detail.Owner.DisplayName,
+		Annotation: detail.Description,
+		Image:      selectImageURL(detail.Images),
+	}}
+	for _, item := range tracks {
+		if item.Track.ID == "" {
+			continue
+		}
+		artists := make([]string, 0, len(item.Track.Artists))
+		for _, a := range item.Track.Artists {
+			artists = append(artists, a.Name)
+		}
+		track := jspfTrack{
+			Title:   item.Track.Name,
+			Creator: strings.Join(artists, ", "),
+		}
+		if url := item.Track.ExternalUrls["spotify"]; url != "" {
+			track.Location = []string{url}
+		}
+		if item.Track.URI != "" {
+			track.Identifier = []string{item.Track.URI}
+		}
+		doc.Playlist.Track = append(doc.Playlist.Track, track)
+	}
+
+	path := filepath.Join(s.dir, detail.ID+".jspf")
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal jspf: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+func (s *jspfSink) Close() error { return nil }
+
+// writePlaylistRecord and writeTrackRecords hold the CSV row-building logic
+// previously inlined in harvester.persistPlaylist/persistTracks; csvSink
+// calls them for every playlist it writes.
+func writePlaylistRecord(store *csvStore, detail *playlistDetail, origin harvestOrigin, relevance relevanceResult) error {
+	if detail == nil {
+		return nil
+	}
+	record := []string{
+		detail.ID,
+		detail.Name,
+		sanitizeCSVField(detail.Description),
+		strconv.Itoa(detail.Followers.Total),
+		strconv.FormatBool(detail.Public),
+		strconv.FormatBool(detail.Collaborative),
+		detail.Owner.ID,
+		detail.Owner.DisplayName,
+		origin.Source,
+		origin.Query,
+		fmt.Sprintf("%.2f", relevance.Score),
+		strings.Join(relevance.KeywordMatches, "|"),
+		strings.Join(relevance.ArtistMatches, "|"),
+		strings.Join(relevance.TrackMatches, "|"),
+		detail.SnapshotID,
+		selectImageURL(detail.Images),
+		strconv.Itoa(detail.Tracks.Total),
+		strconv.Itoa(relevance.FreshnessDays),
+		time.Now().UTC().Format(time.RFC3339),
+	}
+	return store.Write(record)
+}
+
+func writeTrackRecords(store *csvStore, playlistID string, items []playlistTrackItem, origin harvestOrigin) error {
+	for _, item := range items {
+		if item.Track.ID == "" {
+			continue
+		}
+		artists := make([]string, 0, len(item.Track.Artists))
+		for _, a := range item.Track.Artists {
+			artists = append(artists, a.Name)
+		}
+		record := []string{
+			playlistID,
+			item.Track.ID,
+			item.Track.Name,
+			strings.Join(artists, "|"),
+			item.Track.Album.ID,
+			item.AddedAt,
+			item.AddedBy.ID,
+			item.Track.ExternalUrls["spotify"],
+			origin.Source,
+			origin.Query,
+		}
+		if err := store.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testPlaylist() *playlistDetail {
+	detail := &playlistDetail{ID: "pl123", Name: "Workout Mix", Description: "gym bangers"}
+	detail.Owner.DisplayName = "dj_test"
+	return detail
+}
+
+func testTracks() []playlistTrackItem {
+	item := playlistTrackItem{}
+	item.Track.ID = "trk1"
+	item.Track.Name = "Eye of the Tiger"
+	item.Track.URI = "spotify:track:trk1"
+	item.Track.ExternalUrls = map[string]string{"spotify": "https://open.spotify.com/track/trk1"}
+	item.Track.Artists = append(item.Track.Artists, struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}{ID: "art1", Name: "Survivor"})
+
+	skipped := playlistTrackItem{} // no track ID: local file or removed track, must be skipped
+	return []playlistTrackItem{item, skipped}
+}
+
+func TestM3U8SinkWritePlaylist(t *testing.T) {
+	dir := t.TempDir()
+	sink := &m3u8Sink{dir: dir}
+
+	if err := sink.WritePlaylist(testPlaylist(), testTracks(), harvestOrigin{}, relevanceResult{}); err != nil {
+		t.Fatalf("WritePlaylist: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "pl123.m3u8"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	out := string(data)
+
+	if !strings.HasPrefix(out, "#EXTM3U\n") {
+		t.Errorf("m3u8 output missing #EXTM3U header, got:\n%s", out)
+	}
+	if !strings.Contains(out, "#EXTINF:-1,Survivor - Eye of the Tiger\n") {
+		t.Errorf("m3u8 output missing expected #EXTINF line, got:\n%s", out)
+	}
+	if !strings.Contains(out, "https://open.spotify.com/track/trk1\n") {
+		t.Errorf("m3u8 output missing track location, got:\n%s", out)
+	}
+	if strings.Count(out, "#EXTINF") != 1 {
+		t.Errorf("m3u8 output should skip the track with no ID, got:\n%s", out)
+	}
+}
+
+func TestJSPFSinkWritePlaylist(t *testing.T) {
+	dir := t.TempDir()
+	sink := &jspfSink{dir: dir}
+
+	if err := sink.WritePlaylist(testPlaylist(), testTracks(), harvestOrigin{}, relevanceResult{}); err != nil {
+		t.Fatalf("WritePlaylist: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "pl123.jspf"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+
+	var doc jspfDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal jspf output: %v", err)
+	}
+	if doc.Playlist.Title != "Workout Mix" {
+		t.Errorf("Playlist.Title = %q, want %q", doc.Playlist.Title, "Workout Mix")
+	}
+	if doc.Playlist.Creator != "dj_test" {
+		t.Errorf("Playlist.Creator = %q, want %q", doc.Playlist.Creator, "dj_test")
+	}
+	if len(doc.Playlist.Track) != 1 {
+		t.Fatalf("Playlist.Track has %d entries, want 1 (the no-ID track should be skipped)", len(doc.Playlist.Track))
+	}
+	track := doc.Playlist.Track[0]
+	if track.Title != "Eye of the Tiger" || track.Creator != "Survivor" {
+		t.Errorf("track = %+v, want Title=Eye of the Tiger Creator=Survivor", track)
+	}
+	if len(track.Location) != 1 || track.Location[0] != "https://open.spotify.com/track/trk1" {
+		t.Errorf("track.Location = %v, want the spotify external url", track.Location)
+	}
+	if len(track.Identifier) != 1 || track.Identifier[0] != "spotify:track:trk1" {
+		t.Errorf("track.Identifier = %v, want the spotify URI", track.Identifier)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/store"
+)
+
+// storeSink is a PlaylistSink backed by the store.DataStore repository
+// layer (SQLite via modernc.org/sqlite, so no CGO). Every write runs inside
+// one store.WithTx transaction, so a playlist and its tracks/artists either
+// all land or none do.
+type storeSink struct {
+	ds store.DataStore
+}
+
+// newSQLiteSink opens (creating and migrating if needed) a SQLite database
+// at path and wraps it as a PlaylistSink.
+func newSQLiteSink(path string) (*storeSink, error) {
+	ds, err := store.NewSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	return &storeSink{ds: ds}, nil
+}
+
+func (s *storeSink) WritePlaylist(detail *playlistDetail, tracks []playlistTrackItem, origin harvestOrigin, relevance relevanceResult) error {
+	if detail == nil {
+		return nil
+	}
+	ctx := context.Background()
+
+	return s.ds.WithTx(ctx, func(tx store.DataStore) error {
+		if err := tx.Playlist().Upsert(ctx, store.Playlist{
+			ID:              detail.ID,
+			Name:            detail.Name,
+			Description:     detail.Description,
+			Followers:       detail.Followers.Total,
+			Public:          detail.Public,
+			Collaborative:   detail.Collaborative,
+			OwnerID:         detail.Owner.ID,
+			OwnerName:       detail.Owner.DisplayName,
+			OriginSource:    origin.Source,
+			OriginQuery:     origin.Query,
+			Score:           relevance.Score,
+			SnapshotID:      detail.SnapshotID,
+			ImageURL:        selectImageURL(detail.Images),
+			TrackTotal:      detail.Tracks.Total,
+			LastRefreshedAt: time.Now().UTC().Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+
+		previous, err := tx.Track().ListingFor(ctx, detail.ID)
+		if err != nil {
+			return err
+		}
+
+		listing := make([]store.PlaylistTrack, 0, len(tracks))
+		for position, item := range tracks {
+			if item.Track.ID == "" {
+				continue
+			}
+			if err := tx.Track().Upsert(ctx, store.Track{ID: item.Track.ID, Name: item.Track.Name, AlbumID: item.Track.Album.ID}); err != nil {
+				return err
+			}
+			for _, artist := range item.Track.Artists {
+				if artist.ID == "" {
+					continue
+				}
+				if err := tx.Track().UpsertArtist(ctx, store.Artist{ID: artist.ID, Name: artist.Name}); err != nil {
+					return err
+				}
+				if err := tx.Track().LinkArtist(ctx, item.Track.ID, artist.ID); err != nil {
+					return err
+				}
+			}
+			listing = append(listing, store.PlaylistTrack{
+				PlaylistID: detail.ID,
+				TrackID:    item.Track.ID,
+				Position:   position,
+				AddedAt:    item.AddedAt,
+				AddedBy:    item.AddedBy.ID,
+			})
+		}
+		if err := tx.Track().ReplaceListing(ctx, detail.ID, listing); err != nil {
+			return err
+		}
+
+		added, removed := diffTrackIDs(previous, listing)
+		return tx.Change().Record(ctx, store.PlaylistChange{
+			PlaylistID:      detail.ID,
+			AddedTrackIDs:   added,
+			RemovedTrackIDs: removed,
+			RunAt:           time.Now().UTC().Format(time.RFC3339),
+		})
+	})
+}
+
+// diffTrackIDs compares the track listing stored from the previous run
+// against the freshly fetched one, so a playlist_changes row records only
+// what actually moved rather than the whole listing every time.
+func diffTrackIDs(previous, current []store.PlaylistTrack) (added, removed []string) {
+	prevIDs := make(map[string]struct{}, len(previous))
+	for _, item := range previous {
+		prevIDs[item.TrackID] = struct{}{}
+	}
+	currIDs := make(map[string]struct{}, len(current))
+	for _, item := range current {
+		currIDs[item.TrackID] = struct{}{}
+	}
+	for id := range currIDs {
+		if _, ok := prevIDs[id]; !ok {
+			added = append(added, id)
+		}
+	}
+	for id := range prevIDs {
+		if _, ok := currIDs[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func (s *storeSink) Close() error {
+	return s.ds.Close()
+}
+
+// sqliteSnapshotStore adapts store.DataStore's SnapshotRepository to the
+// snapshotStore interface, so a --export sqlite/both run persists snapshot
+// IDs in the same database as the rest of the dataset and survives
+// restarts without a separate --state JSON file.
+type sqliteSnapshotStore struct {
+	ds store.DataStore
+}
+
+func (s *sqliteSnapshotStore) IsUnchanged(id, snapshot string) bool {
+	if snapshot == "" {
+		return false
+	}
+	prev, ok, err := s.ds.Snapshot().Get(context.Background(), id)
+	if err != nil {
+		log.Printf("sqlite snapshot lookup for %s failed: %v", id, err)
+		return false
+	}
+	return ok && prev == snapshot
+}
+
+func (s *sqliteSnapshotStore) Existed(id string) bool {
+	_, ok, err := s.ds.Snapshot().Get(context.Background(), id)
+	if err != nil {
+		log.Printf("sqlite snapshot lookup for %s failed: %v", id, err)
+		return false
+	}
+	return ok
+}
+
+func (s *sqliteSnapshotStore) Update(id, snapshot string) {
+	if snapshot == "" {
+		return
+	}
+	if err := s.ds.Snapshot().Set(context.Background(), id, snapshot); err != nil {
+		log.Printf("sqlite snapshot update for %s failed: %v", id, err)
+	}
+}
+
+// Save is a no-op: every Update already commits its own transaction.
+func (s *sqliteSnapshotStore) Save() error { return nil }
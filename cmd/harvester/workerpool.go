@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// markSeedRun records that query has just been dequeued, when a
+// store.SeedRepository is wired up (--export sqlite/both). Failures are
+// logged, not fatal - it's bookkeeping, not the harvest itself.
+func (h *harvester) markSeedRun(ctx context.Context, query seedQuery) {
+	if h.seedRepo == nil {
+		return
+	}
+	if err := h.seedRepo.MarkRun(ctx, query.Query, query.Source); err != nil {
+		log.Printf("mark seed run for %q/%q failed: %v", query.Source, query.Query, err)
+	}
+}
+
+// sourceBucket holds the still-undequeued seedQuery values for one Source
+// (e.g. "mood+genre", "artist"), in generation order.
+type sourceBucket struct {
+	source string
+	items  []seedQuery
+	next   int
+}
+
+// fairSeedQueue dequeues seedQuery values in plain round-robin order
+// across Source buckets, one query per non-empty bucket per turn, so a
+// source that dominates by sheer volume (the mood+genre cartesian product)
+// can't starve rarer ones (artist, track) of worker time. Buckets are not
+// weighted: every Source gets the same share of turns regardless of its
+// size. perSourceCap, if positive, additionally bounds how many
+// queries are ever taken from a single source in one run.
+type fairSeedQueue struct {
+	mu      sync.Mutex
+	buckets []*sourceBucket
+	cursor  int
+}
+
+func newFairSeedQueue(queries []seedQuery, perSourceCap int) *fairSeedQueue {
+	order := make([]string, 0, 8)
+	bySource := make(map[string]*sourceBucket)
+	for _, q := range queries {
+		b, ok := bySource[q.Source]
+		if !ok {
+			b = &sourceBucket{source: q.Source}
+			bySource[q.Source] = b
+			order = append(order, q.Source)
+		}
+		if perSourceCap > 0 && len(b.items) >= perSourceCap {
+			continue
+		}
+		b.items = append(b.items, q)
+	}
+	buckets := make([]*sourceBucket, 0, len(order))
+	for _, source := range order {
+		buckets = append(buckets, bySource[source])
+	}
+	return &fairSeedQueue{buckets: buckets}
+}
+
+// Len returns the total number of queries still to be dequeued plus those
+// already dequeued - i.e. the size of the (possibly capped) work set.
+func (q *fairSeedQueue) Len() int {
+	total := 0
+	for _, b := range q.buckets {
+		total += len(b.items)
+	}
+	return total
+}
+
+// Dequeue returns the next query, rotating across buckets so each Source
+// gets a turn before any bucket is visited twice. ok is false once every
+// bucket is exhausted.
+func (q *fairSeedQueue) Dequeue() (seedQuery, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := 0; i < len(q.buckets); i++ {
+		idx := (q.cursor + i) % len(q.buckets)
+		b := q.buckets[idx]
+		if b.next < len(b.items) {
+			item := b.items[b.next]
+			b.next++
+			q.cursor = idx + 1
+			return item, true
+		}
+	}
+	return seedQuery{}, false
+}
+
+// harvestSearch drains queries through a pool of h.opts.Workers goroutines
+// that share the client's rate limiter, fed by a fairSeedQueue so no single
+// Source can crowd out the others. Progress is reported every 5s.
+func (h *harvester) harvestSearch(ctx context.Context, queries []seedQuery) error {
+	workers := h.opts.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	pages := h.opts.MaxSearchPages
+	if pages <= 0 {
+		pages = 1
+	}
+
+	queue := newFairSeedQueue(queries, h.opts.PerSourceCap)
+	total := queue.Len()
+	log.Printf("search harvest starting: %d queries across %d sources, %d workers", total, len(queue.buckets), workers)
+
+	var queriesDone int64
+	stopProgress := h.reportSearchProgress(&queriesDone, total)
+	defer stopProgress()
+
+	workCh := make(chan seedQuery, workers)
+	go func() {
+		defer close(workCh)
+		for {
+			q, ok := queue.Dequeue()
+			if !ok {
+				return
+			}
+			select {
+			case workCh <- q:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var errs multiErr
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for query := range workCh {
+				errs.Add(h.harvestQuery(ctx, query, pages))
+				atomic.AddInt64(&queriesDone, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errs.ErrorOrNil()
+}
+
+// harvestQuery pages through the search results for a single seedQuery and
+// processes every playlist found. It's the unit of work a search worker
+// pulls off the fair queue.
+func (h *harvester) harvestQuery(ctx context.Context, query seedQuery, pages int) error {
+	h.markSeedRun(ctx, query)
+	var errs multiErr
+	for page := 0; page < pages; page++ {
+		offset := page * 50
+		pageData, err := h.client.searchPlaylists(ctx, query.Query, offset)
+		if err != nil {
+			errs.Add(fmt.Errorf("query %q offset %d: %w", query.Query, offset, err))
+			break
+		}
+		if len(pageData.Items) == 0 {
+			break
+		}
+		for _, pl := range pageData.Items {
+			if pl.ID == "" {
+				continue
+			}
+			if err := h.limiter.Wait(ctx); err != nil {
+				errs.Add(err)
+				continue
+			}
+			origin := harvestOrigin{Source: fmt.Sprintf("search:%s", query.Source), Query: query.Query}
+			if err := h.processPlaylist(ctx, pl.ID, origin); err != nil {
+				errs.Add(fmt.Errorf("playlist %s: %w", pl.ID, err))
+			}
+		}
+		if pageData.Next == "" {
+			break
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// reportSearchProgress logs queries done/total, playlists persisted and the
+// current request QPS every 5s until the returned stop func is called, which
+// blocks until the reporter goroutine has exited.
+func (h *harvester) reportSearchProgress(queriesDone *int64, total int) func() {
+	stop := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		lastRequests := h.client.RequestCount()
+		lastTick := time.Now()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				now := time.Now()
+				requests := h.client.RequestCount()
+				qps := 0.0
+				if elapsed := now.Sub(lastTick).Seconds(); elapsed > 0 {
+					qps = float64(requests-lastRequests) / elapsed
+				}
+				lastRequests, lastTick = requests, now
+				log.Printf("search harvest progress: %d/%d queries done, %d playlists persisted, %.2f req/s",
+					atomic.LoadInt64(queriesDone), total, h.stats.snapshotWritten(), qps)
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-exited
+	}
+}
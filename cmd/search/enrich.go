@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/dataset"
+	"github.com/kirbs-btw/spotify-playlist-dataset/enrich"
+	"github.com/kirbs-btw/spotify-playlist-dataset/spotify"
+)
+
+// runEnrich reads every track_id/artist_id pair out of songsFile (as written
+// by csvSearchSink) and resolves audio features plus first-artist genres for
+// them via enrich.FeatureFetcher, writing the result to featuresFile.
+// FeatureFetcher caches resolved tracks at cachePath, so a repeated --enrich
+// pass only calls Spotify's /v1/audio-features and /v1/artists endpoints for
+// track IDs it hasn't already resolved.
+func runEnrich(ctx context.Context, client *spotify.Client, songsFile, featuresFile, cachePath string) error {
+	refs, err := readTrackRefs(songsFile)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", songsFile, err)
+	}
+
+	fetcher, err := enrich.NewFeatureFetcher(client, cachePath)
+	if err != nil {
+		return fmt.Errorf("load feature cache: %w", err)
+	}
+
+	rows, err := fetcher.Fetch(ctx, refs)
+	if err != nil {
+		return fmt.Errorf("fetch audio features: %w", err)
+	}
+	if err := fetcher.Save(); err != nil {
+		log.Printf("Fehler beim Speichern des Feature-Caches: %v", err)
+	}
+
+	return writeFeaturesCSV(featuresFile, rows)
+}
+
+// readTrackRefs parses songsFile's track_id/artist_id columns into
+// enrich.TrackRefs, skipping the header row.
+func readTrackRefs(songsFile string) ([]enrich.TrackRef, error) {
+	file, err := os.Open(songsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	trackIdx, artistIdx := -1, -1
+	for i, col := range header {
+		switch col {
+		case "track_id":
+			trackIdx = i
+		case "artist_id":
+			artistIdx = i
+		}
+	}
+	if trackIdx == -1 || artistIdx == -1 {
+		return nil, fmt.Errorf("%s is missing track_id/artist_id columns", songsFile)
+	}
+
+	var refs []enrich.TrackRef
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rec[trackIdx] == "" {
+			continue
+		}
+		refs = append(refs, enrich.TrackRef{TrackID: rec[trackIdx], ArtistID: rec[artistIdx]})
+	}
+	return refs, nil
+}
+
+// writeFeaturesCSV rewrites featuresFile from scratch with the full set of
+// resolved rows, since FeatureFetcher.Fetch returns every requested track's
+// row (cached or freshly fetched) rather than only what's new this run.
+func writeFeaturesCSV(path string, rows []dataset.Row) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	header := dataset.Schemas[dataset.TableAudioFeatures]
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		rec := make([]string, len(header))
+		for i, col := range header {
+			rec[i] = row[col]
+		}
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+
+	"github.com/joho/godotenv"
+	zmbspotify "github.com/zmb3/spotify/v2"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/auth"
+	"github.com/kirbs-btw/spotify-playlist-dataset/crawler"
+	"github.com/kirbs-btw/spotify-playlist-dataset/spotify"
+)
+
+// spotifySearchRateLimit is the observed request ceiling for this keyword
+// search sweep (~180/min) before Spotify starts handing back 429s. main
+// converts it to per-second and passes it as crawler.SeedConfig.QPS, the
+// rate at which the crawler's shared limiter admits search-result pages
+// regardless of how many --workers are running.
+const spotifySearchRateLimit = 180
+
+func main() {
+	envFile := flag.String("env", ".env", "Path to .env file")
+	keyword_idx := flag.String("kw_idx", "44", "idx shift in the keywords file")
+	keyword_file := flag.String("kw_file", "keywords_en.txt", "file of the keywords")
+	playlist_file_name := flag.String("pl_file_name", "data/playlists.csv", "file to save playlists to (--format=csv only)")
+	song_file_name := flag.String("s_file_name", "data/songs.csv", "file of the save songs to (--format=csv only)")
+	format := flag.String("format", "csv", "Output sink: csv, sqlite, or parquet")
+	dataDir := flag.String("data-dir", "data", "Directory the sqlite/parquet sinks write into")
+	workers := flag.Int("workers", 4, "Number of concurrent workers draining the keyword search queue")
+	authMode := flag.String("auth", "client", "Authentication mode: client (client-credentials, public catalog only) or user (Authorization Code + PKCE, also fetches the logged-in user's own and followed playlists)")
+	redirectURI := flag.String("redirect-uri", "http://127.0.0.1:8888/callback", "Redirect URI registered for this app in the Spotify dashboard (user auth mode)")
+	tokenFile := flag.String("token-file", "data/.spotify_token.enc", "Path to the encrypted token store (user auth mode)")
+	checkpointFile := flag.String("checkpoint-file", "data/search_checkpoint.json", "Path to the per-keyword search checkpoint")
+	seenFile := flag.String("seen-file", "data/search_seen.json", "Path to the seen-playlist dedup store")
+	resume := flag.Bool("resume", false, "Resume from an existing checkpoint/seen store instead of starting a fresh sweep")
+	enrichFlag := flag.Bool("enrich", false, "After crawling, fetch audio features and artist genres for every track in --s_file_name (requires --format=csv)")
+	featuresFile := flag.String("features-file", "data/songs_features.csv", "Where --enrich writes danceability/energy/.../genres rows")
+	featureCache := flag.String("feature-cache", "data/.search_feature_cache.json", "Path to the --enrich resume cache, keyed by track ID")
+	resolveFlag := flag.Bool("resolve", false, "After crawling, resolve every track in --s_file_name against MusicBrainz and Bandcamp and write a cross_refs.csv (requires --format=csv)")
+	crossRefsFile := flag.String("cross-refs-file", "data/cross_refs.csv", "Where --resolve writes isrc/mbid/bandcamp_url/match_confidence rows")
+	musicBrainzCache := flag.String("musicbrainz-cache", "data/.search_musicbrainz_cache.json", "Disk cache of MusicBrainz lookups (including negative hits), used when --resolve is set")
+	flag.Parse()
+	// exp.: go run ./cmd/search --env=.env
+	fmt.Println("envF:", envFile)
+
+	// load .env
+	err := godotenv.Load(*envFile)
+	if err != nil {
+		log.Fatal("Fehler beim Laden der .env Datei: ", err)
+	}
+	clientID := os.Getenv("CLIENT_ID")
+	clientSecret := os.Getenv("CLIENT_SECRET")
+
+	// Ctrl-C used to just kill the process mid-sweep; cancelling ctx instead
+	// stops the crawler from handing out new queries and lets in-flight
+	// writeDiscoveredPlaylist calls finish, so the deferred sink Close()s below still
+	// run and flush whatever was written, and the checkpoint below reflects
+	// real progress rather than a half-written offset.
+	ctx, cancel := context.WithCancel(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		<-sigCh
+		fmt.Println("Interrupt received, draining in-flight requests before exit...")
+		cancel()
+	}()
+
+	// get a Spotify client, client-credentials by default or Authorization
+	// Code + PKCE if --auth=user unlocks the logged-in user's own and
+	// followed playlists.
+	var client *spotify.Client
+	switch *authMode {
+	case "client":
+		c, err := spotify.NewClient(ctx, clientID, clientSecret)
+		if err != nil {
+			log.Fatalf("Fehler beim Erstellen des Spotify-Clients: %v", err)
+		}
+		client = c
+	case "user":
+		c, err := userAuthClient(ctx, clientID, clientSecret, *redirectURI, *tokenFile)
+		if err != nil {
+			log.Fatalf("Fehler bei der Nutzer-Authentifizierung: %v", err)
+		}
+		client = c
+	default:
+		log.Fatalf("unbekannter --auth Modus %q (erwartet client oder user)", *authMode)
+	}
+
+	if !*resume {
+		_ = os.Remove(*checkpointFile)
+		_ = os.Remove(*seenFile)
+	}
+
+	sink, err := newSearchSink(*format, *dataDir, *playlist_file_name, *song_file_name)
+	if err != nil {
+		log.Fatalf("Fehler beim Öffnen des Sinks: %v", err)
+	}
+	defer sink.Close()
+
+	// List of common words
+	// get keywords
+	// Open the file
+	file_path := fmt.Sprintf("keywords/%s", *keyword_file)
+    file, err := os.Open(file_path)
+    if err != nil {
+        fmt.Println("Error opening file:", err)
+        return
+    }
+    defer file.Close()
+
+	var keywords []string
+
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        keyword := scanner.Text()
+		keywords = append(keywords, keyword)
+    }
+
+	if err := scanner.Err(); err != nil {
+        fmt.Println("Error reading file:", err)
+        return
+    }
+
+	idx_shift, err := strconv.Atoi(*keyword_idx)
+	if err != nil {
+		fmt.Println("The index shift was not an integer")
+		return
+	}
+	keywords = keywords[idx_shift:]
+
+	seenStore, err := crawler.LoadSeenStore(*seenFile)
+	if err != nil {
+		log.Fatalf("Fehler beim Laden des Seen-Stores: %v", err)
+	}
+
+	checkpoint, err := crawler.LoadCheckpoint(*checkpointFile, keywords)
+	if err != nil {
+		log.Fatalf("Fehler beim Laden des Checkpoints: %v", err)
+	}
+
+	cfg := crawler.SeedConfig{Queries: keywords, Workers: *workers, QPS: float64(spotifySearchRateLimit) / 60}
+	crawl := crawler.New(client, cfg, seenStore, func(ctx context.Context, playlistID, query string) error {
+		fmt.Printf("Current query: %s\n", query)
+		return writeDiscoveredPlaylist(ctx, client, playlistID, sink)
+	})
+	if err := crawl.Run(ctx, checkpoint); err != nil {
+		log.Printf("Fehler beim Crawlen: %v", err)
+	}
+	if err := seenStore.Save(); err != nil {
+		log.Printf("Fehler beim Speichern des Seen-Stores: %v", err)
+	}
+
+	if *authMode == "user" {
+		if err := fetchCurrentUserPlaylists(ctx, client, seenStore, sink); err != nil {
+			log.Printf("Fehler beim Holen der eigenen Playlists: %v", err)
+		}
+		if err := seenStore.Save(); err != nil {
+			log.Printf("Fehler beim Speichern des Seen-Stores: %v", err)
+		}
+	}
+
+	if *enrichFlag {
+		if *format != "csv" {
+			log.Printf("--enrich benötigt --format=csv (aktuell %q), übersprungen", *format)
+		} else if err := runEnrich(ctx, client, *song_file_name, *featuresFile, *featureCache); err != nil {
+			log.Printf("Fehler bei der Feature-Anreicherung: %v", err)
+		}
+	}
+
+	if *resolveFlag {
+		if *format != "csv" {
+			log.Printf("--resolve benötigt --format=csv (aktuell %q), übersprungen", *format)
+		} else if err := runResolve(ctx, *song_file_name, *crossRefsFile, *musicBrainzCache); err != nil {
+			log.Printf("Fehler bei der Cross-Ref-Auflösung: %v", err)
+		}
+	}
+}
+
+// userAuthClient runs the Authorization Code + PKCE flow (prompting for
+// consent only if no valid token is already persisted at tokenFile) and
+// returns a Client authenticated as the logged-in user. Identical to
+// main.go's userAuthClient - both entry points share the auth package so a
+// token persisted by one can be reused by the other if pointed at the same
+// --token-file.
+func userAuthClient(ctx context.Context, clientID, clientSecret, redirectURI, tokenFile string) (*spotify.Client, error) {
+	store := auth.NewFileTokenStore(tokenFile, clientSecret)
+
+	tok, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load persisted token: %w", err)
+	}
+
+	if tok == nil {
+		flow := &auth.PKCEFlow{ClientID: clientID, RedirectURI: redirectURI, Scopes: auth.DefaultScopes}
+		tok, err = flow.Authenticate(ctx, func(url string) {
+			fmt.Println("Open this URL to authorize the app:")
+			fmt.Println(url)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("pkce flow: %w", err)
+		}
+		if err := store.Save(tok); err != nil {
+			return nil, fmt.Errorf("persist token: %w", err)
+		}
+	}
+
+	httpClient := auth.HTTPClient(ctx, clientID, tok, store)
+	return spotify.NewClientFromHTTP(httpClient), nil
+}
+
+// searchCSVSink wraps a csv.Writer with a mutex so concurrent search workers
+// can share one playlists/songs file without interleaving partial rows,
+// mirroring cmd/harvester's csvStore. Named distinctly from cmd/harvester's
+// csvSink/PlaylistSink, which back that separate entry point.
+type searchCSVSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *csv.Writer
+}
+
+// newSearchCSVSink opens/creates filename and writes header if the file is new.
+func newSearchCSVSink(filename string, header []string) (*searchCSVSink, error) {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", filename, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("stat %s: %w", filename, err)
+	}
+	writer := csv.NewWriter(file)
+	if info.Size() == 0 {
+		if err := writer.Write(header); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("write header to %s: %w", filename, err)
+		}
+		writer.Flush()
+	}
+
+	return &searchCSVSink{file: file, writer: writer}, nil
+}
+
+// Write appends rec under the sink's lock and flushes immediately, the
+// same per-row durability the original single-goroutine writer had.
+func (s *searchCSVSink) Write(rec []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writer.Write(rec); err != nil {
+		return err
+	}
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+func (s *searchCSVSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writer.Flush()
+	return s.file.Close()
+}
+
+// writeDiscoveredPlaylist fetches full metadata and tracks for playlistID and
+// writes them to sink. Used as the crawler.Crawler's PlaylistHandler, so it
+// only ever runs once per playlist ID thanks to the crawler's own SeenStore
+// check.
+func writeDiscoveredPlaylist(ctx context.Context, client *spotify.Client, playlistID string, sink Sink) error {
+	pl, err := client.GetPlaylist(ctx, zmbspotify.ID(playlistID))
+	if err != nil {
+		return fmt.Errorf("get playlist %s: %w", playlistID, err)
+	}
+	return writePlaylistTracks(ctx, client, pl.SimplePlaylist, sink)
+}
+
+// writePlaylistTracks fetches pl's tracks and writes the playlist row only
+// once that succeeds, matching the original behavior of skipping playlists
+// whose tracks can't be fetched.
+func writePlaylistTracks(ctx context.Context, client *spotify.Client, pl zmbspotify.SimplePlaylist, sink Sink) error {
+	var tracks []zmbspotify.PlaylistTrack
+	err := client.GetPlaylistTracks(ctx, pl.ID, func(items []zmbspotify.PlaylistTrack) error {
+		tracks = append(tracks, items...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("get tracks: %w", err)
+	}
+
+	if err := sink.WritePlaylist(pl); err != nil {
+		return fmt.Errorf("write playlist: %w", err)
+	}
+
+	for position, item := range tracks {
+		if err := sink.WriteTrack(string(pl.ID), item, position); err != nil {
+			log.Printf("Fehler beim Schreiben Track %s: %v", item.Track.ID, err)
+		}
+	}
+	return nil
+}
+
+// fetchCurrentUserPlaylists pages through the logged-in user's own and
+// followed playlists (only reachable in --auth=user mode) and writes them
+// through the same sink as the keyword search sweep, deduping against the
+// same seenStore so a playlist already crawled via search isn't rewritten.
+func fetchCurrentUserPlaylists(ctx context.Context, client *spotify.Client, seenStore *crawler.SeenStore, sink Sink) error {
+	return client.CurrentUserPlaylists(ctx, func(page spotify.PlaylistPage) error {
+		for _, pl := range page.Playlists {
+			if !seenStore.MarkSeen(string(pl.ID)) {
+				continue
+			}
+			if err := writePlaylistTracks(ctx, client, pl, sink); err != nil {
+				log.Printf("Fehler bei eigener Playlist %s: %v", pl.ID, err)
+			}
+		}
+		return nil
+	})
+}
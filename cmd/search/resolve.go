@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/dataset"
+	"github.com/kirbs-btw/spotify-playlist-dataset/metadata"
+	"github.com/kirbs-btw/spotify-playlist-dataset/resolver"
+)
+
+// searchResolverUserAgent identifies this tool to MusicBrainz and Bandcamp
+// per their API etiquette.
+const searchResolverUserAgent = "spotify-playlist-dataset/1.0 (+https://github.com/kirbs-btw/spotify-playlist-dataset)"
+
+// runResolve reads every track_id/isrc/artist_name/track_name row out of
+// songsFile (as written by csvSearchSink) and resolves each against
+// MusicBrainz and Bandcamp via resolver.Resolver, writing the result to
+// crossRefsFile. Like runEnrich, this is a second pass over the already
+// harvested songs CSV rather than something wired into the crawl itself.
+func runResolve(ctx context.Context, songsFile, crossRefsFile, musicBrainzCachePath string) error {
+	refs, err := readResolveRefs(songsFile)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", songsFile, err)
+	}
+
+	musicBrainz, err := metadata.NewMusicBrainzSource(musicBrainzCachePath, searchResolverUserAgent)
+	if err != nil {
+		return fmt.Errorf("load musicbrainz cache: %w", err)
+	}
+	res := resolver.New(musicBrainz, resolver.NewBandcampSource(searchResolverUserAgent))
+
+	rows := make([]dataset.Row, 0, len(refs))
+	for _, ref := range refs {
+		row, err := res.Resolve(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("resolve track %s: %w", ref.TrackID, err)
+		}
+		rows = append(rows, row)
+	}
+	if err := musicBrainz.Save(); err != nil {
+		log.Printf("Fehler beim Speichern des MusicBrainz-Caches: %v", err)
+	}
+
+	return writeCrossRefsCSV(crossRefsFile, rows)
+}
+
+// readResolveRefs parses songsFile's track_id/isrc/artist_name/track_name
+// columns into resolver.TrackRefs, skipping the header row.
+func readResolveRefs(songsFile string) ([]resolver.TrackRef, error) {
+	file, err := os.Open(songsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	idx := map[string]int{"track_id": -1, "isrc": -1, "artist_name": -1, "track_name": -1}
+	for i, col := range header {
+		if _, ok := idx[col]; ok {
+			idx[col] = i
+		}
+	}
+	for col, i := range idx {
+		if i == -1 {
+			return nil, fmt.Errorf("%s is missing %s column", songsFile, col)
+		}
+	}
+
+	var refs []resolver.TrackRef
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rec[idx["track_id"]] == "" {
+			continue
+		}
+		refs = append(refs, resolver.TrackRef{
+			TrackID: rec[idx["track_id"]],
+			ISRC:    rec[idx["isrc"]],
+			Artist:  rec[idx["artist_name"]],
+			Title:   rec[idx["track_name"]],
+		})
+	}
+	return refs, nil
+}
+
+// writeCrossRefsCSV rewrites crossRefsFile from scratch with the full set
+// of resolved rows, mirroring writeFeaturesCSV's all-rows-every-run shape.
+func writeCrossRefsCSV(path string, rows []dataset.Row) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	header := dataset.Schemas[dataset.TableCrossRefs]
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		rec := make([]string, len(header))
+		for i, col := range header {
+			rec[i] = row[col]
+		}
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
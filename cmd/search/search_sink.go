@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	zmbspotify "github.com/zmb3/spotify/v2"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/dataset"
+	"github.com/kirbs-btw/spotify-playlist-dataset/store"
+)
+
+// Sink is the write path for the keyword search sweep, selectable via
+// --format so the same crawl can land in CSV, SQLite, or Parquet without
+// writeDiscoveredPlaylist caring which one is active.
+type Sink interface {
+	WritePlaylist(pl zmbspotify.SimplePlaylist) error
+	WriteTrack(playlistID string, item zmbspotify.PlaylistTrack, position int) error
+	Close() error
+}
+
+// newSearchSink builds the Sink named by format ("csv", "sqlite", or
+// "parquet"). dir is used by the sqlite/parquet sinks; plFile/songFile are
+// used by the csv sink.
+func newSearchSink(format, dir, plFile, songFile string) (Sink, error) {
+	switch format {
+	case "csv":
+		return newCSVSearchSink(plFile, songFile)
+	case "sqlite":
+		return newSQLiteSearchSink(dir + "/search.db")
+	case "parquet":
+		return newParquetSearchSink(dir)
+	default:
+		return nil, fmt.Errorf("unknown --format %q (want csv, sqlite, or parquet)", format)
+	}
+}
+
+// csvSearchSink is the original playlists.csv/songs.csv pair, now behind the
+// Sink interface instead of being called directly from fetchAndSave.
+type csvSearchSink struct {
+	playlists *searchCSVSink
+	songs     *searchCSVSink
+}
+
+func newCSVSearchSink(plFile, songFile string) (*csvSearchSink, error) {
+	playlists, err := newSearchCSVSink(plFile, []string{"playlist_id", "playlist_name", "tracks_href"})
+	if err != nil {
+		return nil, err
+	}
+	songs, err := newSearchCSVSink(songFile, []string{"playlist_id", "track_id", "track_name", "track_external_urls", "release_date", "artist_name", "artist_id", "isrc"})
+	if err != nil {
+		playlists.Close()
+		return nil, err
+	}
+	return &csvSearchSink{playlists: playlists, songs: songs}, nil
+}
+
+func (s *csvSearchSink) WritePlaylist(pl zmbspotify.SimplePlaylist) error {
+	return s.playlists.Write([]string{string(pl.ID), pl.Name, pl.Endpoint})
+}
+
+func (s *csvSearchSink) WriteTrack(playlistID string, item zmbspotify.PlaylistTrack, _ int) error {
+	track := item.Track
+	artists := make([]string, 0, len(track.Artists))
+	firstArtistID := ""
+	for i, a := range track.Artists {
+		artists = append(artists, a.Name)
+		if i == 0 {
+			firstArtistID = string(a.ID)
+		}
+	}
+	return s.songs.Write([]string{
+		playlistID,
+		string(track.ID),
+		track.Name,
+		track.ExternalURLs["spotify"],
+		track.Album.ReleaseDate,
+		strings.Join(artists, ", "),
+		firstArtistID,
+		track.ExternalIDs["isrc"],
+	})
+}
+
+func (s *csvSearchSink) Close() error {
+	err := s.playlists.Close()
+	if songsErr := s.songs.Close(); err == nil {
+		err = songsErr
+	}
+	return err
+}
+
+// sqliteSearchSink writes through the store package's repositories, the same
+// SQLite-via-modernc.org/sqlite persistence cmd/harvester/sqlite_sink.go already
+// uses, so playlists/tracks/artists get the same schemas and primary keys
+// rather than a second hand-rolled schema. That schema declares no foreign
+// keys: playlist_tracks.playlist_id is not constrained to an existing playlist.
+// WriteTrack buffers each playlist's listing in memory and replaces it
+// wholesale on every call, since TrackRepository.ReplaceListing takes the
+// full ordered listing rather than a single-row append; at search-sweep
+// playlist sizes this is cheap.
+type sqliteSearchSink struct {
+	ds store.DataStore
+
+	mu       sync.Mutex
+	listings map[string][]store.PlaylistTrack
+}
+
+func newSQLiteSearchSink(path string) (*sqliteSearchSink, error) {
+	ds, err := store.NewSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteSearchSink{ds: ds, listings: make(map[string][]store.PlaylistTrack)}, nil
+}
+
+func (s *sqliteSearchSink) WritePlaylist(pl zmbspotify.SimplePlaylist) error {
+	ctx := context.Background()
+	return s.ds.Playlist().Upsert(ctx, store.Playlist{
+		ID:         string(pl.ID),
+		Name:       pl.Name,
+		OwnerID:    string(pl.Owner.ID),
+		OwnerName:  pl.Owner.DisplayName,
+		SnapshotID: pl.SnapshotID,
+		TrackTotal: int(pl.Tracks.Total),
+	})
+}
+
+func (s *sqliteSearchSink) WriteTrack(playlistID string, item zmbspotify.PlaylistTrack, position int) error {
+	ctx := context.Background()
+	track := item.Track
+	if track.ID == "" {
+		return nil
+	}
+
+	if err := s.ds.Track().Upsert(ctx, store.Track{ID: string(track.ID), Name: track.Name, AlbumID: string(track.Album.ID)}); err != nil {
+		return err
+	}
+	for _, artist := range track.Artists {
+		if artist.ID == "" {
+			continue
+		}
+		if err := s.ds.Track().UpsertArtist(ctx, store.Artist{ID: string(artist.ID), Name: artist.Name}); err != nil {
+			return err
+		}
+		if err := s.ds.Track().LinkArtist(ctx, string(track.ID), string(artist.ID)); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.listings[playlistID] = append(s.listings[playlistID], store.PlaylistTrack{
+		PlaylistID: playlistID,
+		TrackID:    string(track.ID),
+		Position:   position,
+		AddedAt:    item.AddedAt,
+		AddedBy:    string(item.AddedBy.ID),
+	})
+	listing := append([]store.PlaylistTrack(nil), s.listings[playlistID]...)
+	s.mu.Unlock()
+
+	return s.ds.Track().ReplaceListing(ctx, playlistID, listing)
+}
+
+func (s *sqliteSearchSink) Close() error {
+	return s.ds.Close()
+}
+
+// parquetSearchSink writes through dataset.ParquetWriter, the dataset
+// package's xitongsys/parquet-go-backed writer, rather than introducing a
+// second Parquet dependency for the same file format.
+type parquetSearchSink struct {
+	w *dataset.ParquetWriter
+}
+
+func newParquetSearchSink(dir string) (*parquetSearchSink, error) {
+	w, err := dataset.NewParquetWriter(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetSearchSink{w: w}, nil
+}
+
+func (s *parquetSearchSink) WritePlaylist(pl zmbspotify.SimplePlaylist) error {
+	return s.w.WriteRow(dataset.TablePlaylists, dataset.Row{
+		"playlist_id": string(pl.ID),
+		"name":        pl.Name,
+		"owner_id":    string(pl.Owner.ID),
+		"owner_name":  pl.Owner.DisplayName,
+		"snapshot_id": pl.SnapshotID,
+		"track_count": strconv.Itoa(int(pl.Tracks.Total)),
+	})
+}
+
+func (s *parquetSearchSink) WriteTrack(playlistID string, item zmbspotify.PlaylistTrack, position int) error {
+	track := item.Track
+	if err := s.w.WriteRow(dataset.TableTracks, dataset.Row{
+		"track_id":    string(track.ID),
+		"name":        track.Name,
+		"album_id":    string(track.Album.ID),
+		"duration_ms": strconv.Itoa(int(track.Duration)),
+	}); err != nil {
+		return err
+	}
+	return s.w.WriteRow(dataset.TablePlaylistTracks, dataset.Row{
+		"playlist_id": playlistID,
+		"track_id":    string(track.ID),
+		"position":    strconv.Itoa(position),
+		"added_at":    item.AddedAt,
+		"added_by":    string(item.AddedBy.ID),
+	})
+}
+
+func (s *parquetSearchSink) Close() error {
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	return s.w.Close()
+}
@@ -0,0 +1,112 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// QueryProgress records how far a single seed query has been paged through:
+// Offset counts the new playlists handled for it so far and LastPlaylistID
+// is the last of them. Crawler.crawlQuery continues that count on a resumed
+// run, but its search still starts from the first result page.
+type QueryProgress struct {
+	Query          string `json:"query"`
+	Offset         int    `json:"offset"`
+	LastPlaylistID string `json:"last_playlist_id"`
+	Done           bool   `json:"done"`
+}
+
+// Checkpoint is the resumable state of a crawl: per-query paging progress
+// plus the queue of queries not yet started.
+type Checkpoint struct {
+	path string
+	mu   sync.Mutex
+
+	Progress map[string]*QueryProgress `json:"progress"`
+	Pending  []string                  `json:"pending"`
+}
+
+// LoadCheckpoint reads path if present, or seeds a fresh checkpoint from
+// queries (every query starts pending at offset 0).
+func LoadCheckpoint(path string, queries []string) (*Checkpoint, error) {
+	cp := &Checkpoint{path: path, Progress: make(map[string]*QueryProgress)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("read checkpoint: %w", err)
+		}
+		cp.Pending = append([]string(nil), queries...)
+		return cp, nil
+	}
+	if len(data) == 0 {
+		cp.Pending = append([]string(nil), queries...)
+		return cp, nil
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("parse checkpoint: %w", err)
+	}
+
+	// Fold in any new queries that weren't part of the checkpointed run.
+	known := make(map[string]struct{}, len(cp.Progress))
+	for q := range cp.Progress {
+		known[q] = struct{}{}
+	}
+	for _, p := range cp.Pending {
+		known[p] = struct{}{}
+	}
+	for _, q := range queries {
+		if _, ok := known[q]; !ok {
+			cp.Pending = append(cp.Pending, q)
+		}
+	}
+	return cp, nil
+}
+
+// NextQuery pops the next pending query, or ("", false) if none remain.
+func (c *Checkpoint) NextQuery() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.Pending) == 0 {
+		return "", false
+	}
+	q := c.Pending[0]
+	c.Pending = c.Pending[1:]
+	return q, true
+}
+
+// Update records progress for query after a page has been processed.
+func (c *Checkpoint) Update(query string, offset int, lastPlaylistID string, done bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Progress[query] = &QueryProgress{Query: query, Offset: offset, LastPlaylistID: lastPlaylistID, Done: done}
+}
+
+// StartOffset returns the recorded Offset for query, or 0 if none.
+func (c *Checkpoint) StartOffset(query string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.Progress[query]; ok {
+		return p.Offset
+	}
+	return 0
+}
+
+// Save persists the checkpoint to disk.
+func (c *Checkpoint) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+	if dir := filepath.Dir(c.path); dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create checkpoint dir: %w", err)
+		}
+	}
+	return os.WriteFile(c.path, data, 0o644)
+}
@@ -0,0 +1,102 @@
+package crawler
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCheckpointFreshSeedsAllQueriesPending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	cp, err := LoadCheckpoint(path, []string{"rock", "pop"})
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if len(cp.Pending) != 2 {
+		t.Fatalf("Pending = %v, want both seed queries", cp.Pending)
+	}
+}
+
+func TestCheckpointNextQueryDrainsPending(t *testing.T) {
+	cp, err := LoadCheckpoint(filepath.Join(t.TempDir(), "checkpoint.json"), []string{"rock", "pop"})
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+
+	q, ok := cp.NextQuery()
+	if !ok || q != "rock" {
+		t.Fatalf("first NextQuery() = (%q, %v), want (rock, true)", q, ok)
+	}
+	q, ok = cp.NextQuery()
+	if !ok || q != "pop" {
+		t.Fatalf("second NextQuery() = (%q, %v), want (pop, true)", q, ok)
+	}
+	if _, ok := cp.NextQuery(); ok {
+		t.Error("NextQuery() on an empty queue returned ok=true")
+	}
+}
+
+func TestCheckpointUpdateAndStartOffset(t *testing.T) {
+	cp, err := LoadCheckpoint(filepath.Join(t.TempDir(), "checkpoint.json"), nil)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if got := cp.StartOffset("rock"); got != 0 {
+		t.Errorf("StartOffset for an unseen query = %d, want 0", got)
+	}
+
+	cp.Update("rock", 50, "abc123", false)
+	if got := cp.StartOffset("rock"); got != 50 {
+		t.Errorf("StartOffset after Update(offset=50) = %d, want 50", got)
+	}
+}
+
+func TestCheckpointSaveAndResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	cp, err := LoadCheckpoint(path, []string{"rock", "pop", "jazz"})
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if _, ok := cp.NextQuery(); !ok {
+		t.Fatal("NextQuery() on fresh checkpoint returned ok=false")
+	}
+	cp.Update("rock", 20, "plid", false)
+	if err := cp.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	resumed, err := LoadCheckpoint(path, []string{"rock", "pop", "jazz"})
+	if err != nil {
+		t.Fatalf("LoadCheckpoint (resume): %v", err)
+	}
+	if got := resumed.StartOffset("rock"); got != 20 {
+		t.Errorf("resumed StartOffset(rock) = %d, want 20", got)
+	}
+	if len(resumed.Pending) != 2 {
+		t.Errorf("resumed Pending = %v, want pop and jazz still queued (rock already popped)", resumed.Pending)
+	}
+}
+
+func TestLoadCheckpointFoldsInNewQueries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	cp, err := LoadCheckpoint(path, []string{"rock"})
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if _, ok := cp.NextQuery(); !ok {
+		t.Fatal("NextQuery() on fresh checkpoint returned ok=false")
+	}
+	cp.Update("rock", 10, "", true)
+	if err := cp.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	resumed, err := LoadCheckpoint(path, []string{"rock", "metal"})
+	if err != nil {
+		t.Fatalf("LoadCheckpoint (with a new query): %v", err)
+	}
+	if len(resumed.Pending) != 1 || resumed.Pending[0] != "metal" {
+		t.Errorf("resumed Pending = %v, want just the new query [metal] (rock was already popped and is tracked in Progress)", resumed.Pending)
+	}
+}
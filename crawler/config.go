@@ -0,0 +1,61 @@
+package crawler
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SeedConfig is the YAML input that seeds a crawl: a flat list of search
+// queries grouped by the dimension they came from (genre, mood, year, ...),
+// plus the tunables for how aggressively to crawl.
+type SeedConfig struct {
+	Genres  []string `yaml:"genres"`
+	Moods   []string `yaml:"moods"`
+	Years   []string `yaml:"years"`
+	Queries []string `yaml:"queries"`
+
+	Workers      int     `yaml:"workers"`
+	QPS          float64 `yaml:"qps"`
+	MaxDepth     int     `yaml:"max_depth"`
+	FollowOwners bool    `yaml:"follow_owners"`
+}
+
+// LoadSeedConfig reads and validates a YAML seed file.
+func LoadSeedConfig(path string) (*SeedConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read seed config: %w", err)
+	}
+	var cfg SeedConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse seed config: %w", err)
+	}
+	cfg.applyDefaults()
+	return &cfg, nil
+}
+
+func (c *SeedConfig) applyDefaults() {
+	if c.Workers <= 0 {
+		c.Workers = 4
+	}
+	if c.QPS <= 0 {
+		c.QPS = 5
+	}
+	if c.MaxDepth <= 0 {
+		c.MaxDepth = 1
+	}
+}
+
+// SeedQueries flattens every configured dimension into one query list, since
+// the crawler treats genre/mood/year/custom queries identically once a
+// search is issued.
+func (c *SeedConfig) SeedQueries() []string {
+	out := make([]string, 0, len(c.Genres)+len(c.Moods)+len(c.Years)+len(c.Queries))
+	out = append(out, c.Genres...)
+	out = append(out, c.Moods...)
+	out = append(out, c.Years...)
+	out = append(out, c.Queries...)
+	return out
+}
@@ -0,0 +1,203 @@
+// Package crawler discovers playlists beyond a fixed list of seed queries:
+// it searches each seed, follows the owners of matching playlists to find
+// more playlists by the same curators, and de-duplicates everything it
+// finds against a persisted seen-set so repeated runs don't re-harvest the
+// same playlist.
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/spotify"
+)
+
+// PlaylistHandler is invoked once per newly-discovered playlist.
+type PlaylistHandler func(ctx context.Context, playlistID string, sourceQuery string) error
+
+// playlistSource is the subset of *spotify.Client the crawler depends on,
+// narrowed to a small interface so crawlQuery/crawlOwners can be exercised
+// against a fake in tests instead of hitting the real Spotify API.
+type playlistSource interface {
+	SearchPlaylists(ctx context.Context, query string, onPage func(spotify.PlaylistPage) error) error
+	GetUserPlaylists(ctx context.Context, userID string, onPage func(spotify.PlaylistPage) error) error
+}
+
+// Crawler coordinates a worker pool over a checkpointed query queue,
+// respecting a global QPS limit and a bound on how many owner-follow hops
+// it will take away from the original seed query.
+type Crawler struct {
+	client  playlistSource
+	handler PlaylistHandler
+	seen    *SeenStore
+	limiter *rate.Limiter
+	cfg     SeedConfig
+	owners  *ownerQueue
+}
+
+// New builds a Crawler. cfg.Workers/QPS/MaxDepth are applied as defaults if
+// unset.
+func New(client *spotify.Client, cfg SeedConfig, seen *SeenStore, handler PlaylistHandler) *Crawler {
+	cfg.applyDefaults()
+	return &Crawler{
+		client:  client,
+		handler: handler,
+		seen:    seen,
+		limiter: rate.NewLimiter(rate.Limit(cfg.QPS), int(cfg.QPS)+1),
+		cfg:     cfg,
+		owners:  newOwnerQueue(),
+	}
+}
+
+// Run drains checkpoint's pending queries across cfg.Workers goroutines,
+// saving progress after every query so a killed run resumes cleanly.
+func (c *Crawler) Run(ctx context.Context, checkpoint *Checkpoint) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, c.cfg.Workers)
+
+	for i := 0; i < c.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				query, ok := checkpoint.NextQuery()
+				if !ok {
+					return
+				}
+				if err := c.crawlQuery(ctx, query, checkpoint); err != nil {
+					errs <- fmt.Errorf("query %q: %w", query, err)
+				}
+				if err := checkpoint.Save(); err != nil {
+					log.Printf("checkpoint save failed: %v", err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	if c.cfg.FollowOwners {
+		if err := c.crawlOwners(ctx); err != nil {
+			return fmt.Errorf("owner follow-up: %w", err)
+		}
+	}
+
+	var firstErr error
+	for err := range errs {
+		log.Printf("crawl error: %v", err)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (c *Crawler) crawlQuery(ctx context.Context, query string, checkpoint *Checkpoint) error {
+	offset := checkpoint.StartOffset(query)
+	lastID := ""
+	err := c.client.SearchPlaylists(ctx, query, func(page spotify.PlaylistPage) error {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		for _, pl := range page.Playlists {
+			if string(pl.ID) == "" {
+				continue
+			}
+			if !c.seen.MarkSeen(string(pl.ID)) {
+				continue
+			}
+			if c.cfg.FollowOwners {
+				c.owners.Add(pl.Owner.ID, 0)
+			}
+			if err := c.handler(ctx, string(pl.ID), query); err != nil {
+				return err
+			}
+			lastID = string(pl.ID)
+			offset++
+		}
+		checkpoint.Update(query, offset, lastID, false)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	checkpoint.Update(query, offset, lastID, true)
+	return nil
+}
+
+// crawlOwners fans out to the playlists owned by every owner discovered
+// during the search pass, up to cfg.MaxDepth hops away from a seed query.
+func (c *Crawler) crawlOwners(ctx context.Context) error {
+	for depth := 0; depth < c.cfg.MaxDepth; depth++ {
+		owners := c.owners.Drain()
+		if len(owners) == 0 {
+			return nil
+		}
+		for _, owner := range owners {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return err
+			}
+			query := fmt.Sprintf("owner:%s", owner)
+			err := c.client.GetUserPlaylists(ctx, owner, func(page spotify.PlaylistPage) error {
+				for _, pl := range page.Playlists {
+					// GetPlaylistsForUser returns playlists owned or
+					// followed by owner; only the owned ones are this
+					// curator's own work, so skip ones they merely follow.
+					if pl.Owner.ID != owner {
+						continue
+					}
+					if !c.seen.MarkSeen(string(pl.ID)) {
+						continue
+					}
+					if err := c.handler(ctx, string(pl.ID), query); err != nil {
+						return err
+					}
+					c.owners.Add(pl.Owner.ID, depth+1)
+				}
+				return nil
+			})
+			if err != nil {
+				log.Printf("follow owner %s: %v", owner, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ownerQueue deduplicates owner IDs discovered while crawling, so the same
+// curator isn't re-queued for every playlist of theirs that shows up.
+type ownerQueue struct {
+	mu      sync.Mutex
+	visited map[string]struct{}
+	pending []string
+}
+
+func newOwnerQueue() *ownerQueue {
+	return &ownerQueue{visited: make(map[string]struct{})}
+}
+
+func (q *ownerQueue) Add(ownerID string, depth int) {
+	if ownerID == "" {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.visited[ownerID]; ok {
+		return
+	}
+	q.visited[ownerID] = struct{}{}
+	q.pending = append(q.pending, ownerID)
+}
+
+func (q *ownerQueue) Drain() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := q.pending
+	q.pending = nil
+	return out
+}
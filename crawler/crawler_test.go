@@ -0,0 +1,120 @@
+package crawler
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	zmbspotify "github.com/zmb3/spotify/v2"
+	"golang.org/x/time/rate"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/spotify"
+)
+
+// fakePlaylistSource is a playlistSource that never makes a network call, so
+// crawlOwners/crawlQuery's call pattern can be asserted directly instead of
+// inferred from whether playlists the API would never return got found.
+type fakePlaylistSource struct {
+	searchCalls     []string
+	userPlaylists   map[string][]zmbspotify.SimplePlaylist
+	userPlaylistErr error
+}
+
+func (f *fakePlaylistSource) SearchPlaylists(ctx context.Context, query string, onPage func(spotify.PlaylistPage) error) error {
+	f.searchCalls = append(f.searchCalls, query)
+	return nil
+}
+
+func (f *fakePlaylistSource) GetUserPlaylists(ctx context.Context, userID string, onPage func(spotify.PlaylistPage) error) error {
+	if f.userPlaylistErr != nil {
+		return f.userPlaylistErr
+	}
+	return onPage(spotify.PlaylistPage{Playlists: f.userPlaylists[userID]})
+}
+
+func newTestCrawler(t *testing.T, client playlistSource, cfg SeedConfig) *Crawler {
+	t.Helper()
+	cfg.applyDefaults()
+	seen, err := LoadSeenStore(filepath.Join(t.TempDir(), "seen.json"))
+	if err != nil {
+		t.Fatalf("LoadSeenStore: %v", err)
+	}
+	return &Crawler{
+		client:  client,
+		handler: func(context.Context, string, string) error { return nil },
+		seen:    seen,
+		limiter: rate.NewLimiter(rate.Limit(cfg.QPS), int(cfg.QPS)+1),
+		cfg:     cfg,
+		owners:  newOwnerQueue(),
+	}
+}
+
+// TestCrawlOwnersListsTheOwnersPlaylistsDirectly guards against crawlOwners
+// regressing back to a free-text SearchPlaylists("owner:<id>", ...) call,
+// which Spotify's catalog search has no filter for and so never actually
+// surfaces a given owner's playlists.
+func TestCrawlOwnersListsTheOwnersPlaylistsDirectly(t *testing.T) {
+	fake := &fakePlaylistSource{
+		userPlaylists: map[string][]zmbspotify.SimplePlaylist{
+			"curator1": {
+				{ID: "pl1", Owner: zmbspotify.User{ID: "curator1"}},
+			},
+		},
+	}
+	c := newTestCrawler(t, fake, SeedConfig{FollowOwners: true, MaxDepth: 1})
+	c.owners.Add("curator1", 0)
+
+	if err := c.crawlOwners(context.Background()); err != nil {
+		t.Fatalf("crawlOwners: %v", err)
+	}
+
+	if len(fake.searchCalls) != 0 {
+		t.Errorf("crawlOwners called SearchPlaylists(%v), want it to use GetUserPlaylists exclusively", fake.searchCalls)
+	}
+}
+
+func TestCrawlOwnersSkipsPlaylistsOnlyFollowedNotOwned(t *testing.T) {
+	fake := &fakePlaylistSource{
+		userPlaylists: map[string][]zmbspotify.SimplePlaylist{
+			"curator1": {
+				{ID: "followed-not-owned", Owner: zmbspotify.User{ID: "someone-else"}},
+			},
+		},
+	}
+	c := newTestCrawler(t, fake, SeedConfig{FollowOwners: true, MaxDepth: 1})
+	c.owners.Add("curator1", 0)
+
+	seenIDs := map[string]bool{}
+	c.handler = func(_ context.Context, playlistID, _ string) error {
+		seenIDs[playlistID] = true
+		return nil
+	}
+
+	if err := c.crawlOwners(context.Background()); err != nil {
+		t.Fatalf("crawlOwners: %v", err)
+	}
+	if seenIDs["followed-not-owned"] {
+		t.Error("crawlOwners handled a playlist the owner only follows, not owns")
+	}
+}
+
+func TestCrawlOwnersDoesNotRequeueVisitedOwner(t *testing.T) {
+	fake := &fakePlaylistSource{
+		userPlaylists: map[string][]zmbspotify.SimplePlaylist{
+			"curator1": {
+				{ID: "pl1", Owner: zmbspotify.User{ID: "curator1"}},
+			},
+		},
+	}
+	c := newTestCrawler(t, fake, SeedConfig{FollowOwners: true, MaxDepth: 2})
+	c.owners.Add("curator1", 0)
+
+	if err := c.crawlOwners(context.Background()); err != nil {
+		t.Fatalf("crawlOwners: %v", err)
+	}
+	// curator1's own playlist re-enqueues curator1, but ownerQueue already
+	// marked curator1 visited, so it should not be queued a second time.
+	if got := c.owners.Drain(); len(got) != 0 {
+		t.Errorf("owners queue after crawlOwners = %v, want empty (curator1 already visited)", got)
+	}
+}
@@ -0,0 +1,84 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// SeenStore is an on-disk key-value set of playlist IDs the crawler has
+// already persisted, so overlapping seed queries don't re-emit the same
+// playlist. It's intentionally a flat JSON file rather than a bloom filter:
+// at the scale of a few hundred thousand playlist IDs the exact set is
+// cheap enough to keep in memory, and unlike a bloom filter it never
+// produces a false positive that silently drops a playlist.
+type SeenStore struct {
+	path  string
+	mu    sync.Mutex
+	ids   map[string]struct{}
+	dirty bool
+}
+
+// LoadSeenStore reads path if it exists, or starts empty.
+func LoadSeenStore(path string) (*SeenStore, error) {
+	s := &SeenStore{path: path, ids: make(map[string]struct{})}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("read seen store: %w", err)
+	}
+	if len(data) == 0 {
+		return s, nil
+	}
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, fmt.Errorf("parse seen store: %w", err)
+	}
+	for _, id := range ids {
+		s.ids[id] = struct{}{}
+	}
+	return s, nil
+}
+
+// MarkSeen records id as seen and reports whether it was new.
+func (s *SeenStore) MarkSeen(id string) (isNew bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ids[id]; ok {
+		return false
+	}
+	s.ids[id] = struct{}{}
+	s.dirty = true
+	return true
+}
+
+// Save flushes the set to disk if it changed since the last Save.
+func (s *SeenStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	ids := make([]string, 0, len(s.ids))
+	for id := range s.ids {
+		ids = append(ids, id)
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return fmt.Errorf("marshal seen store: %w", err)
+	}
+	if dir := filepath.Dir(s.path); dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create seen store dir: %w", err)
+		}
+	}
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write seen store: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
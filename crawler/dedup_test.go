@@ -0,0 +1,66 @@
+package crawler
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSeenStoreMarkSeen(t *testing.T) {
+	s, err := LoadSeenStore(filepath.Join(t.TempDir(), "seen.json"))
+	if err != nil {
+		t.Fatalf("LoadSeenStore: %v", err)
+	}
+
+	if isNew := s.MarkSeen("pl1"); !isNew {
+		t.Error("MarkSeen on a never-seen id returned isNew=false")
+	}
+	if isNew := s.MarkSeen("pl1"); isNew {
+		t.Error("MarkSeen on an already-seen id returned isNew=true")
+	}
+}
+
+func TestSeenStoreSaveAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen.json")
+
+	s, err := LoadSeenStore(path)
+	if err != nil {
+		t.Fatalf("LoadSeenStore: %v", err)
+	}
+	s.MarkSeen("pl1")
+	s.MarkSeen("pl2")
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded, err := LoadSeenStore(path)
+	if err != nil {
+		t.Fatalf("LoadSeenStore (reload): %v", err)
+	}
+	if isNew := reloaded.MarkSeen("pl1"); isNew {
+		t.Error("pl1 should already be marked seen after reload")
+	}
+	if isNew := reloaded.MarkSeen("pl3"); !isNew {
+		t.Error("pl3 was never seen before, MarkSeen should report isNew=true")
+	}
+}
+
+func TestSeenStoreSaveIsNoOpWhenNotDirty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen.json")
+	s, err := LoadSeenStore(path)
+	if err != nil {
+		t.Fatalf("LoadSeenStore: %v", err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save on an empty, untouched store: %v", err)
+	}
+}
+
+func TestLoadSeenStoreMissingFileStartsEmpty(t *testing.T) {
+	s, err := LoadSeenStore(filepath.Join(t.TempDir(), "does-not-exist.json"))
+	if err != nil {
+		t.Fatalf("LoadSeenStore on a missing file: %v", err)
+	}
+	if isNew := s.MarkSeen("pl1"); !isNew {
+		t.Error("MarkSeen on a fresh store returned isNew=false")
+	}
+}
@@ -0,0 +1,120 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// defaultBatchSize is how many rows CSVWriter buffers per table before
+// flushing them to disk together.
+const defaultBatchSize = 200
+
+// CSVWriter writes each table to its own <dir>/<table>.csv file with a
+// fixed header taken from Schemas. Rows are buffered per table and flushed
+// in one batch, so a crash mid-run loses at most one partial batch instead
+// of corrupting the file with a half-written row.
+type CSVWriter struct {
+	dir       string
+	batchSize int
+	tables    map[Table]*csvTable
+}
+
+type csvTable struct {
+	file    *os.File
+	writer  *csv.Writer
+	pending []Row
+	table   Table
+}
+
+// NewCSVWriter creates dir if needed and opens (or creates, with header) the
+// CSV file for every table in Schemas.
+func NewCSVWriter(dir string) (*CSVWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create dataset dir: %w", err)
+	}
+	w := &CSVWriter{dir: dir, batchSize: defaultBatchSize, tables: make(map[Table]*csvTable)}
+	for table := range Schemas {
+		t, err := openCSVTable(dir, table)
+		if err != nil {
+			w.Close()
+			return nil, err
+		}
+		w.tables[table] = t
+	}
+	return w, nil
+}
+
+func openCSVTable(dir string, table Table) (*csvTable, error) {
+	path := filepath.Join(dir, string(table)+".csv")
+	_, statErr := os.Stat(path)
+	exists := statErr == nil
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	writer := csv.NewWriter(file)
+	if !exists {
+		if err := writer.Write(Schemas[table]); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("write header for %s: %w", table, err)
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("flush header for %s: %w", table, err)
+		}
+	}
+	return &csvTable{file: file, writer: writer, table: table}, nil
+}
+
+// WriteRow buffers row for table, flushing the batch once it reaches
+// CSVWriter's batch size.
+func (w *CSVWriter) WriteRow(table Table, row Row) error {
+	t, ok := w.tables[table]
+	if !ok {
+		return fmt.Errorf("unknown table %q", table)
+	}
+	t.pending = append(t.pending, row)
+	if len(t.pending) >= w.batchSize {
+		return w.flushTable(t)
+	}
+	return nil
+}
+
+// Flush writes every buffered row across all tables.
+func (w *CSVWriter) Flush() error {
+	for _, t := range w.tables {
+		if err := w.flushTable(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *CSVWriter) flushTable(t *csvTable) error {
+	for _, row := range t.pending {
+		if err := t.writer.Write(columns(row, t.table)); err != nil {
+			return fmt.Errorf("write row to %s: %w", t.table, err)
+		}
+	}
+	t.pending = t.pending[:0]
+	t.writer.Flush()
+	return t.writer.Error()
+}
+
+// Close flushes and closes every table's file.
+func (w *CSVWriter) Close() error {
+	var firstErr error
+	for _, t := range w.tables {
+		if err := w.flushTable(t); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err := t.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
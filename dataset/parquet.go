@@ -0,0 +1,129 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetParallelism is the number of goroutines xitongsys/parquet-go uses
+// to encode row groups; our batches are small enough that more would just
+// add overhead.
+const parquetParallelism = 1
+
+// ParquetWriter writes each table to its own <dir>/<table>.parquet file,
+// using a flat string-typed schema generated from Schemas so downstream ML
+// tooling gets columnar access to the same data the CSV sink produces.
+type ParquetWriter struct {
+	dir    string
+	tables map[Table]*parquetTable
+}
+
+type parquetTable struct {
+	file   source.ParquetFile
+	writer *writer.JSONWriter
+}
+
+// NewParquetWriter creates dir if needed and opens a Parquet writer for
+// every table in Schemas.
+func NewParquetWriter(dir string) (*ParquetWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create dataset dir: %w", err)
+	}
+	w := &ParquetWriter{dir: dir, tables: make(map[Table]*parquetTable)}
+	for table := range Schemas {
+		t, err := openParquetTable(dir, table)
+		if err != nil {
+			w.Close()
+			return nil, err
+		}
+		w.tables[table] = t
+	}
+	return w, nil
+}
+
+func openParquetTable(dir string, table Table) (*parquetTable, error) {
+	path := filepath.Join(dir, string(table)+".parquet")
+	file, err := local.NewLocalFileWriter(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	jw, err := writer.NewJSONWriter(jsonSchema(table), file, parquetParallelism)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("create parquet writer for %s: %w", table, err)
+	}
+	return &parquetTable{file: file, writer: jw}, nil
+}
+
+// jsonSchema builds the xitongsys/parquet-go JSON schema string for table:
+// every column is an optional UTF8-encoded string, since Row itself is
+// already string-typed (callers format numeric fields before WriteRow).
+func jsonSchema(table Table) string {
+	type field struct {
+		Tag string `json:"Tag"`
+	}
+	type schema struct {
+		Tag    string  `json:"Tag"`
+		Fields []field `json:"Fields"`
+	}
+
+	s := schema{Tag: "name=" + string(table) + ", repetitiontype=REQUIRED"}
+	for _, col := range Schemas[table] {
+		s.Fields = append(s.Fields, field{
+			Tag: fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL", col),
+		})
+	}
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// WriteRow marshals row's columns (in schema order) to JSON and writes it
+// as one Parquet record.
+func (w *ParquetWriter) WriteRow(table Table, row Row) error {
+	t, ok := w.tables[table]
+	if !ok {
+		return fmt.Errorf("unknown table %q", table)
+	}
+	record := make(map[string]string, len(Schemas[table]))
+	for _, col := range Schemas[table] {
+		record[col] = row[col]
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal row for %s: %w", table, err)
+	}
+	if err := t.writer.Write(string(data)); err != nil {
+		return fmt.Errorf("write parquet row for %s: %w", table, err)
+	}
+	return nil
+}
+
+// Flush forces every table's buffered row group to disk.
+func (w *ParquetWriter) Flush() error {
+	for table, t := range w.tables {
+		if err := t.writer.Flush(true); err != nil {
+			return fmt.Errorf("flush parquet %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// Close flushes the final row group and closes every table's file.
+func (w *ParquetWriter) Close() error {
+	var firstErr error
+	for table, t := range w.tables {
+		if err := t.writer.WriteStop(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("finalize parquet %s: %w", table, err)
+		}
+		if err := t.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,108 @@
+// Package dataset replaces the old single flat CSV ("name,age,score") with a
+// real multi-table schema for harvested Spotify data, and lets the same rows
+// be written to CSV and/or Parquet through one Writer interface.
+package dataset
+
+// Table names the fixed set of tables a harvest run produces. Keeping this
+// as a closed set (rather than an arbitrary string) keeps the column order
+// for each table centralized in Schemas below.
+type Table string
+
+const (
+	TablePlaylists      Table = "playlists"
+	TableTracks         Table = "tracks"
+	TablePlaylistTracks Table = "playlist_tracks"
+	TableArtists        Table = "artists"
+	TableAudioFeatures  Table = "audio_features"
+	TableCrossRefs      Table = "cross_refs"
+)
+
+// Schemas defines the stable column order for every table. A Writer
+// implementation must write columns in this order regardless of the order
+// fields are set on a Row.
+var Schemas = map[Table][]string{
+	TablePlaylists: {
+		"playlist_id", "name", "description", "owner_id", "owner_name",
+		"followers", "snapshot_id", "track_count",
+	},
+	TableTracks: {
+		"track_id", "name", "album_id", "duration_ms", "isrc",
+	},
+	TablePlaylistTracks: {
+		"playlist_id", "track_id", "position", "added_at", "added_by",
+	},
+	TableArtists: {
+		"artist_id", "name",
+	},
+	TableAudioFeatures: {
+		"track_id", "danceability", "energy", "tempo", "valence", "key",
+		"loudness", "acousticness", "instrumentalness", "speechiness",
+		"liveness", "time_signature", "genres",
+	},
+	TableCrossRefs: {
+		"spotify_track_id", "isrc", "mbid", "bandcamp_url", "match_confidence",
+	},
+}
+
+// Row is a single record for a table, keyed by column name. Writer
+// implementations look up columns via Schemas[table] so callers don't need
+// to care about column order, only column names.
+type Row map[string]string
+
+// Writer is the sink every table write goes through. Implementations are
+// expected to batch writes internally and flush them together so a crash
+// mid-batch can't leave a table with some rows written and others lost.
+type Writer interface {
+	WriteRow(table Table, row Row) error
+	Flush() error
+	Close() error
+}
+
+// MultiWriter fans a single WriteRow/Flush/Close call out to every
+// configured sink (e.g. CSV and Parquet at once), so a harvest run can
+// populate both without the caller knowing which sinks are active.
+type MultiWriter struct {
+	writers []Writer
+}
+
+// NewMultiWriter combines writers into one Writer.
+func NewMultiWriter(writers ...Writer) *MultiWriter {
+	return &MultiWriter{writers: writers}
+}
+
+func (m *MultiWriter) WriteRow(table Table, row Row) error {
+	for _, w := range m.writers {
+		if err := w.WriteRow(table, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MultiWriter) Flush() error {
+	for _, w := range m.writers {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MultiWriter) Close() error {
+	var firstErr error
+	for _, w := range m.writers {
+		if err := w.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func columns(row Row, table Table) []string {
+	schema := Schemas[table]
+	out := make([]string, len(schema))
+	for i, col := range schema {
+		out[i] = row[col]
+	}
+	return out
+}
@@ -0,0 +1,9 @@
+// Package migration embeds the SQL files that build and evolve the SQLite
+// dataset schema, so store can apply them without the binary needing a
+// filesystem path to this repo at runtime.
+package migration
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS
@@ -0,0 +1,208 @@
+// Package enrich fetches Spotify's audio-features for harvested tracks and
+// merges them into the dataset's audio_features table; it does not call the
+// audio-analysis endpoint. This is so the dataset is useful for playlist-recommendation
+// research rather than just a list of titles.
+package enrich
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	zmbspotify "github.com/zmb3/spotify/v2"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/dataset"
+	"github.com/kirbs-btw/spotify-playlist-dataset/spotify"
+)
+
+// TrackRef is a track queued for feature enrichment, paired with its first
+// artist so FeatureFetcher can join genres onto the audio-features row
+// without a second lookup pass over the dataset.
+type TrackRef struct {
+	TrackID  string
+	ArtistID string
+}
+
+// FeatureFetcher deduplicates track IDs across playlists and caches
+// resolved audio features to disk, so re-crawls that see the same track in
+// multiple playlists don't repeat the audio-features call for it.
+type FeatureFetcher struct {
+	client    *spotify.Client
+	cachePath string
+
+	mu    sync.Mutex
+	cache map[string]dataset.Row
+	dirty bool
+}
+
+// NewFeatureFetcher loads any previously cached features at cachePath.
+func NewFeatureFetcher(client *spotify.Client, cachePath string) (*FeatureFetcher, error) {
+	f := &FeatureFetcher{client: client, cachePath: cachePath, cache: make(map[string]dataset.Row)}
+	if cachePath == "" {
+		return f, nil
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return nil, fmt.Errorf("read feature cache: %w", err)
+	}
+	if len(data) == 0 {
+		return f, nil
+	}
+	if err := json.Unmarshal(data, &f.cache); err != nil {
+		return nil, fmt.Errorf("parse feature cache: %w", err)
+	}
+	return f, nil
+}
+
+// Fetch resolves audio features (plus genres joined from each track's first
+// artist) for every ref in tracks, serving already-cached track IDs from
+// memory and only calling Spotify for the rest. This is what makes a
+// repeated --enrich pass resumable: a track already present in the feature
+// cache is skipped instead of re-fetched. The returned rows are in
+// dataset.TableAudioFeatures column order and can be written through a
+// dataset.Writer directly.
+func (f *FeatureFetcher) Fetch(ctx context.Context, tracks []TrackRef) ([]dataset.Row, error) {
+	unique := dedupeRefs(tracks)
+
+	f.mu.Lock()
+	var missing []zmbspotify.ID
+	missingArtist := make(map[string]string, len(unique))
+	rows := make([]dataset.Row, 0, len(unique))
+	for _, ref := range unique {
+		if row, ok := f.cache[ref.TrackID]; ok {
+			rows = append(rows, row)
+			continue
+		}
+		missing = append(missing, zmbspotify.ID(ref.TrackID))
+		missingArtist[ref.TrackID] = ref.ArtistID
+	}
+	f.mu.Unlock()
+
+	if len(missing) == 0 {
+		return rows, nil
+	}
+
+	features, err := f.client.GetAudioFeatures(ctx, missing)
+	if err != nil {
+		return nil, fmt.Errorf("fetch audio features: %w", err)
+	}
+
+	genresByArtist, err := f.fetchGenres(ctx, missingArtist)
+	if err != nil {
+		return nil, fmt.Errorf("fetch artist genres: %w", err)
+	}
+
+	f.mu.Lock()
+	for _, feat := range features {
+		if feat == nil {
+			continue
+		}
+		row := audioFeaturesRow(feat)
+		row["genres"] = strings.Join(genresByArtist[missingArtist[string(feat.ID)]], "|")
+		f.cache[string(feat.ID)] = row
+		f.dirty = true
+		rows = append(rows, row)
+	}
+	f.mu.Unlock()
+
+	return rows, nil
+}
+
+// fetchGenres batches the distinct artist IDs referenced by missingArtist
+// against /v1/artists and returns each artist's genres, so a track's genres
+// column can be joined on its first artist.
+func (f *FeatureFetcher) fetchGenres(ctx context.Context, missingArtist map[string]string) (map[string][]string, error) {
+	seen := make(map[string]struct{}, len(missingArtist))
+	var artistIDs []zmbspotify.ID
+	for _, artistID := range missingArtist {
+		if artistID == "" {
+			continue
+		}
+		if _, ok := seen[artistID]; ok {
+			continue
+		}
+		seen[artistID] = struct{}{}
+		artistIDs = append(artistIDs, zmbspotify.ID(artistID))
+	}
+	if len(artistIDs) == 0 {
+		return nil, nil
+	}
+
+	artists, err := f.client.GetArtists(ctx, artistIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	genres := make(map[string][]string, len(artists))
+	for _, a := range artists {
+		if a == nil {
+			continue
+		}
+		genres[string(a.ID)] = a.Genres
+	}
+	return genres, nil
+}
+
+// Save flushes the feature cache to disk if it changed since the last Save.
+func (f *FeatureFetcher) Save() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.dirty || f.cachePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(f.cache)
+	if err != nil {
+		return fmt.Errorf("marshal feature cache: %w", err)
+	}
+	if dir := filepath.Dir(f.cachePath); dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create feature cache dir: %w", err)
+		}
+	}
+	if err := os.WriteFile(f.cachePath, data, 0o644); err != nil {
+		return fmt.Errorf("write feature cache: %w", err)
+	}
+	f.dirty = false
+	return nil
+}
+
+func audioFeaturesRow(feat *zmbspotify.AudioFeatures) dataset.Row {
+	return dataset.Row{
+		"track_id":         string(feat.ID),
+		"danceability":     strconv.FormatFloat(float64(feat.Danceability), 'f', 4, 64),
+		"energy":           strconv.FormatFloat(float64(feat.Energy), 'f', 4, 64),
+		"tempo":            strconv.FormatFloat(float64(feat.Tempo), 'f', 4, 64),
+		"valence":          strconv.FormatFloat(float64(feat.Valence), 'f', 4, 64),
+		"key":              strconv.Itoa(int(feat.Key)),
+		"loudness":         strconv.FormatFloat(float64(feat.Loudness), 'f', 4, 64),
+		"acousticness":     strconv.FormatFloat(float64(feat.Acousticness), 'f', 4, 64),
+		"instrumentalness": strconv.FormatFloat(float64(feat.Instrumentalness), 'f', 4, 64),
+		"speechiness":      strconv.FormatFloat(float64(feat.Speechiness), 'f', 4, 64),
+		"liveness":         strconv.FormatFloat(float64(feat.Liveness), 'f', 4, 64),
+		"time_signature":   strconv.Itoa(int(feat.TimeSignature)),
+	}
+}
+
+func dedupeRefs(refs []TrackRef) []TrackRef {
+	seen := make(map[string]struct{}, len(refs))
+	out := make([]TrackRef, 0, len(refs))
+	for _, ref := range refs {
+		if ref.TrackID == "" {
+			continue
+		}
+		if _, ok := seen[ref.TrackID]; ok {
+			continue
+		}
+		seen[ref.TrackID] = struct{}{}
+		out = append(out, ref)
+	}
+	return out
+}
@@ -1,116 +1,307 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"encoding/json"
-	"encoding/csv"
-	"github.com/go-resty/resty/v2"
+	"strconv"
+	"sync"
+
 	"github.com/joho/godotenv"
+	zmbspotify "github.com/zmb3/spotify/v2"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/auth"
+	"github.com/kirbs-btw/spotify-playlist-dataset/crawler"
+	"github.com/kirbs-btw/spotify-playlist-dataset/dataset"
+	"github.com/kirbs-btw/spotify-playlist-dataset/enrich"
+	"github.com/kirbs-btw/spotify-playlist-dataset/metrics"
+	"github.com/kirbs-btw/spotify-playlist-dataset/spotify"
 )
 
 func main() {
+	authMode := flag.String("auth", "client", "Authentication mode: client (client-credentials, public catalog only) or user (Authorization Code + PKCE, unlocks private/collaborative playlists)")
+	redirectURI := flag.String("redirect-uri", "http://127.0.0.1:8888/callback", "Redirect URI registered for this app in the Spotify dashboard (user auth mode)")
+	tokenFile := flag.String("token-file", "data/.spotify_token.enc", "Path to the encrypted token store (user auth mode)")
+	outDir := flag.String("out-dir", "data", "Directory to write the playlists/tracks/... CSV tables to")
+	metricsAddr := flag.String("metrics-addr", ":9090", "Address to serve Prometheus /metrics on")
+	dryRun := flag.Bool("dry-run", false, "Perform all API calls but skip dataset writes, to estimate quota usage before a full crawl")
+	seedFile := flag.String("seed-file", "seeds.yaml", "Path to the YAML seed config (genres/moods/years/queries plus worker/QPS/depth tunables) driving the crawl")
+	checkpointFile := flag.String("checkpoint-file", "data/checkpoint.json", "Path to the crawl checkpoint (pending queries, per-query offsets)")
+	seenFile := flag.String("seen-file", "data/seen.json", "Path to the seen-playlist dedup store")
+	resume := flag.Bool("resume", false, "Resume from an existing checkpoint/seen store instead of starting a fresh crawl")
+	enrichFlag := flag.Bool("enrich", false, "After crawling, fetch audio features and artist genres for every discovered track")
+	featureCache := flag.String("feature-cache", "data/.feature_cache.json", "Path to the --enrich resume cache, keyed by track ID")
+	flag.Parse()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
 	// .env laden
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Fehler beim Laden der .env Datei")
+	if err := godotenv.Load(); err != nil {
+		log.Error("failed to load .env file", "error", err)
+		os.Exit(1)
 	}
 
 	clientID := os.Getenv("CLIENT_ID")
 	clientSecret := os.Getenv("CLIENT_SECRET")
 
-	token, err := getSpotifyToken(clientID, clientSecret)
+	go func() {
+		log.Info("serving metrics", "addr", *metricsAddr)
+		if err := metrics.Serve(*metricsAddr); err != nil {
+			log.Warn("metrics server stopped", "error", err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	var client *spotify.Client
+	switch *authMode {
+	case "client":
+		c, err := spotify.NewClient(ctx, clientID, clientSecret)
+		if err != nil {
+			log.Error("failed to create spotify client", "error", err)
+			os.Exit(1)
+		}
+		client = c
+	case "user":
+		c, err := userAuthClient(ctx, clientID, clientSecret, *redirectURI, *tokenFile)
+		if err != nil {
+			log.Error("failed user authentication", "error", err)
+			os.Exit(1)
+		}
+		client = c
+	default:
+		log.Error("unknown --auth mode", "mode", *authMode)
+		os.Exit(1)
+	}
+
+	var store *dataset.CSVWriter
+	if !*dryRun {
+		s, err := dataset.NewCSVWriter(*outDir)
+		if err != nil {
+			log.Error("failed to create dataset writer", "error", err)
+			os.Exit(1)
+		}
+		store = s
+		defer func() {
+			if err := store.Close(); err != nil {
+				log.Error("failed to close dataset writer", "error", err)
+			}
+		}()
+	}
+
+	seedCfg, err := crawler.LoadSeedConfig(*seedFile)
+	if err != nil {
+		log.Error("failed to load seed config", "error", err)
+		os.Exit(1)
+	}
+
+	if !*resume {
+		_ = os.Remove(*checkpointFile)
+		_ = os.Remove(*seenFile)
+	}
+
+	seenStore, err := crawler.LoadSeenStore(*seenFile)
+	if err != nil {
+		log.Error("failed to load seen store", "error", err)
+		os.Exit(1)
+	}
+
+	checkpoint, err := crawler.LoadCheckpoint(*checkpointFile, seedCfg.SeedQueries())
 	if err != nil {
-		log.Fatalf("Fehler beim Holen des Tokens: %v", err)
+		log.Error("failed to load checkpoint", "error", err)
+		os.Exit(1)
+	}
+
+	h := &harvester{client: client, store: store, dryRun: *dryRun, log: log, writtenArtists: make(map[string]bool)}
+
+	crawl := crawler.New(client, *seedCfg, seenStore, h.handlePlaylist)
+	if err := crawl.Run(ctx, checkpoint); err != nil {
+		log.Error("crawl failed", "error", err)
+	}
+	if err := seenStore.Save(); err != nil {
+		log.Error("failed to save seen store", "error", err)
+	}
+
+	if *dryRun {
+		log.Info("dry run complete", "playlists_seen", h.total)
+		return
 	}
+	log.Info("harvest complete", "playlists_written", h.total)
 
-	// Jetzt API Call mit dem Token
-	searchSpotify(token, "workout")
+	if *enrichFlag {
+		if err := runEnrich(ctx, client, store, h.trackRefs, *featureCache); err != nil {
+			log.Error("feature enrichment failed", "error", err)
+		}
+	}
 }
 
-func getSpotifyToken(clientID, clientSecret string) (string, error) {
-	client := resty.New()
+// harvester adapts the crawler's one-playlist-at-a-time PlaylistHandler to
+// this entry point's dataset writes: a crawled playlist's own row plus every
+// track/artist it contains, so the playlists/tracks/playlist_tracks/artists
+// tables stay in sync rather than only playlists ever getting populated.
+// store is not safe for concurrent use, and crawler.Run drives handlePlaylist
+// from cfg.Workers goroutines at once, so every access is serialized under mu.
+type harvester struct {
+	client *spotify.Client
+	store  *dataset.CSVWriter
+	dryRun bool
+	log    *slog.Logger
 
-	resp, err := client.R().
-		SetBasicAuth(clientID, clientSecret).
-		SetHeader("Content-Type", "application/x-www-form-urlencoded").
-		SetBody("grant_type=client_credentials").
-		Post("https://accounts.spotify.com/api/token")
+	mu             sync.Mutex
+	total          int
+	writtenArtists map[string]bool
+	trackRefs      []enrich.TrackRef
+}
+
+func (h *harvester) handlePlaylist(ctx context.Context, playlistID, sourceQuery string) error {
+	pl, err := h.client.GetPlaylist(ctx, zmbspotify.ID(playlistID))
+	if err != nil {
+		return fmt.Errorf("get playlist %s: %w", playlistID, err)
+	}
+	h.log.Debug("found playlist", "id", pl.ID, "name", pl.Name, "tracks", pl.Tracks.Total, "query", sourceQuery)
 
+	var tracks []zmbspotify.PlaylistTrack
+	err = h.client.GetPlaylistTracks(ctx, pl.ID, func(items []zmbspotify.PlaylistTrack) error {
+		tracks = append(tracks, items...)
+		return nil
+	})
 	if err != nil {
-		return "", err
+		return fmt.Errorf("get tracks for %s: %w", playlistID, err)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
-		return "", err
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.total++
+	if h.dryRun {
+		return nil
 	}
 
-	token, ok := result["access_token"].(string)
-	if !ok {
-		return "", fmt.Errorf("Access Token nicht gefunden im Response")
+	if err := h.store.WriteRow(dataset.TablePlaylists, playlistRow(pl.SimplePlaylist)); err != nil {
+		return fmt.Errorf("write playlist %s: %w", playlistID, err)
 	}
+	metrics.PlaylistsWrittenTotal.Inc()
 
-	return token, nil
+	for position, item := range tracks {
+		if err := h.writeTrackLocked(playlistID, item, position); err != nil {
+			h.log.Warn("failed to write track", "playlist_id", playlistID, "track_id", item.Track.ID, "error", err)
+		}
+	}
+	return nil
 }
 
+// writeTrackLocked writes a track's tracks/playlist_tracks/artists rows and
+// queues it for --enrich. Callers must hold h.mu.
+func (h *harvester) writeTrackLocked(playlistID string, item zmbspotify.PlaylistTrack, position int) error {
+	track := item.Track
+	if track.ID == "" {
+		return nil
+	}
 
-func searchSpotify(token, query string) {
-	client := resty.New()
+	if err := h.store.WriteRow(dataset.TableTracks, dataset.Row{
+		"track_id":    string(track.ID),
+		"name":        track.Name,
+		"album_id":    string(track.Album.ID),
+		"duration_ms": strconv.Itoa(int(track.Duration)),
+		"isrc":        track.ExternalIDs["isrc"],
+	}); err != nil {
+		return fmt.Errorf("write track %s: %w", track.ID, err)
+	}
 
-	resp, err := client.R().
-		SetAuthToken(token).
-		SetQueryParams(map[string]string{
-			"q":    query,
-			"type": "playlist",
-			"limit": "50",
-		}).
-		Get("https://api.spotify.com/v1/search")
+	if err := h.store.WriteRow(dataset.TablePlaylistTracks, dataset.Row{
+		"playlist_id": playlistID,
+		"track_id":    string(track.ID),
+		"position":    strconv.Itoa(position),
+		"added_at":    item.AddedAt,
+		"added_by":    string(item.AddedBy.ID),
+	}); err != nil {
+		return fmt.Errorf("write playlist_track %s/%s: %w", playlistID, track.ID, err)
+	}
+	metrics.TracksWrittenTotal.Inc()
 
-	if err != nil {
-		log.Fatal(err)
+	firstArtistID := ""
+	for i, a := range track.Artists {
+		if i == 0 {
+			firstArtistID = string(a.ID)
+		}
+		if a.ID == "" || h.writtenArtists[string(a.ID)] {
+			continue
+		}
+		if err := h.store.WriteRow(dataset.TableArtists, dataset.Row{"artist_id": string(a.ID), "name": a.Name}); err != nil {
+			return fmt.Errorf("write artist %s: %w", a.ID, err)
+		}
+		h.writtenArtists[string(a.ID)] = true
 	}
 
-	fmt.Println("Response Body:")
-	fmt.Println(string(resp.Body()))
+	h.trackRefs = append(h.trackRefs, enrich.TrackRef{TrackID: string(track.ID), ArtistID: firstArtistID})
+	return nil
 }
 
-func do_csv_stuff() {
-	// Open or create the CSV file in append mode
-	file, err := os.OpenFile("output.csv", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// runEnrich fetches audio features and first-artist genres for every track
+// handlePlaylist collected and writes them to the audio_features table.
+// FeatureFetcher caches resolved tracks at cachePath, so a resumed --enrich
+// pass only calls Spotify for track IDs it hasn't already resolved.
+func runEnrich(ctx context.Context, client *spotify.Client, store dataset.Writer, refs []enrich.TrackRef, cachePath string) error {
+	fetcher, err := enrich.NewFeatureFetcher(client, cachePath)
 	if err != nil {
-		fmt.Printf("Failed to open file: %v\n", err)
-		return
+		return fmt.Errorf("load feature cache: %w", err)
 	}
-	defer file.Close()
 
-	// Create a new CSV writer
-	writer := csv.NewWriter(file)
+	rows, err := fetcher.Fetch(ctx, refs)
+	if err != nil {
+		return fmt.Errorf("fetch audio features: %w", err)
+	}
+	if err := fetcher.Save(); err != nil {
+		return fmt.Errorf("save feature cache: %w", err)
+	}
 
-	// Example usage of WriteToCSV
-	if err := WriteToCSV(writer, "Alice", 30, 88.5); err != nil {
-		fmt.Printf("Error writing to CSV: %v\n", err)
+	for _, row := range rows {
+		if err := store.WriteRow(dataset.TableAudioFeatures, row); err != nil {
+			return fmt.Errorf("write audio features %s: %w", row["track_id"], err)
+		}
 	}
+	return nil
 }
 
+func playlistRow(pl zmbspotify.SimplePlaylist) dataset.Row {
+	return dataset.Row{
+		"playlist_id": string(pl.ID),
+		"name":        pl.Name,
+		"description": pl.Description,
+		"owner_id":    pl.Owner.ID,
+		"owner_name":  pl.Owner.DisplayName,
+		"followers":   "",
+		"snapshot_id": pl.SnapshotID,
+		"track_count": strconv.Itoa(int(pl.Tracks.Total)),
+	}
+}
 
-func WriteToCSV(writer *csv.Writer, name string, age int, score float64) error {
-	// string conversion
-	ageStr := strconv.Itoa(age)
-	scoreStr := fmt.Sprintf("%.2f", score)
-
-	record := []string{name, ageStr, scoreStr}
+// userAuthClient runs the Authorization Code + PKCE flow (prompting for
+// consent only if no valid token is already persisted at tokenFile) and
+// returns a Client authenticated as the logged-in user.
+func userAuthClient(ctx context.Context, clientID, clientSecret, redirectURI, tokenFile string) (*spotify.Client, error) {
+	store := auth.NewFileTokenStore(tokenFile, clientSecret)
 
-	if err := writer.Write(record); err != nil {
-		return fmt.Errorf("error writing record to CSV: %v", err)
+	tok, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load persisted token: %w", err)
 	}
 
-	// flushing
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+	if tok == nil {
+		flow := &auth.PKCEFlow{ClientID: clientID, RedirectURI: redirectURI, Scopes: auth.DefaultScopes}
+		tok, err = flow.Authenticate(ctx, func(url string) {
+			fmt.Println("Open this URL to authorize the app:")
+			fmt.Println(url)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("pkce flow: %w", err)
+		}
+		if err := store.Save(tok); err != nil {
+			return nil, fmt.Errorf("persist token: %w", err)
+		}
 	}
 
-	return nil
+	httpClient := auth.HTTPClient(ctx, clientID, tok, store)
+	return spotify.NewClientFromHTTP(httpClient), nil
 }
@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// cacheEntry is what's persisted per lookup key. Found distinguishes "we
+// looked this up and MusicBrainz has nothing" from "we haven't looked this
+// up yet", so a negative hit is cached exactly like a positive one and
+// isn't re-queried on the next run.
+type cacheEntry struct {
+	Found      bool        `json:"found"`
+	Enrichment *Enrichment `json:"enrichment,omitempty"`
+}
+
+// diskCache is a JSON-file-backed lookup cache, following the same
+// load-once/dirty-flag/explicit-Save shape as enrich.FeatureFetcher's
+// audio-features cache.
+type diskCache struct {
+	path string
+
+	mu    sync.Mutex
+	data  map[string]cacheEntry
+	dirty bool
+}
+
+func newDiskCache(path string) (*diskCache, error) {
+	c := &diskCache{path: path, data: make(map[string]cacheEntry)}
+	if path == "" {
+		return c, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("read metadata cache: %w", err)
+	}
+	if len(raw) == 0 {
+		return c, nil
+	}
+	if err := json.Unmarshal(raw, &c.data); err != nil {
+		return nil, fmt.Errorf("parse metadata cache: %w", err)
+	}
+	return c, nil
+}
+
+func (c *diskCache) get(key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.data[key]
+	return entry, ok
+}
+
+func (c *diskCache) put(key string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = entry
+	c.dirty = true
+}
+
+// save flushes the cache to disk if it changed since the last save.
+func (c *diskCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty || c.path == "" {
+		return nil
+	}
+	raw, err := json.Marshal(c.data)
+	if err != nil {
+		return fmt.Errorf("marshal metadata cache: %w", err)
+	}
+	if dir := filepath.Dir(c.path); dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create metadata cache dir: %w", err)
+		}
+	}
+	if err := os.WriteFile(c.path, raw, 0o644); err != nil {
+		return fmt.Errorf("write metadata cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,49 @@
+// Package metadata resolves harvested Spotify tracks against external
+// catalogs (MusicBrainz, and potentially others behind the same interface)
+// to recover canonical identifiers - artist MBIDs, release group IDs,
+// ISRC, original release date, label and genre tags - that Spotify's own
+// API doesn't expose. It mirrors the pattern of cmd/harvester's
+// SeedProvider: one small interface, several possible backends, callers
+// depend only on the interface.
+package metadata
+
+import (
+	"context"
+	"time"
+)
+
+// TrackQuery is what the harvester knows about a track going into a
+// lookup: the ISRC if Spotify returned one, plus the artist/title/duration
+// needed for a fuzzy fallback match when it didn't.
+type TrackQuery struct {
+	ISRC     string
+	Title    string
+	Artist   string
+	Duration time.Duration
+}
+
+// Enrichment is the set of fields a Source may be able to resolve for a
+// TrackQuery. Fields Source couldn't determine are left zero-valued.
+type Enrichment struct {
+	RecordingMBID       string
+	ArtistMBID          string
+	ReleaseGroupID      string
+	ISRC                string
+	OriginalReleaseDate string
+	Label               string
+	Genres              []string
+
+	// MatchMethod records how the match was made ("isrc" or "fuzzy"), and
+	// MatchScore is the fuzzy-match confidence (1.0 for an ISRC hit), so a
+	// track_enrichment consumer can filter out low-confidence guesses
+	// without re-deriving them.
+	MatchMethod string
+	MatchScore  float64
+}
+
+// Source resolves a single TrackQuery against an external catalog. It
+// returns a nil Enrichment (not an error) when the catalog has no match,
+// so a miss and cache negative-hit both flow through the same path.
+type Source interface {
+	Resolve(ctx context.Context, q TrackQuery) (*Enrichment, error)
+}
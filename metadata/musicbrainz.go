@@ -0,0 +1,315 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"golang.org/x/time/rate"
+)
+
+// musicBrainzRatePerSec is MusicBrainz's documented anonymous rate limit:
+// one request per second, enforced per source IP.
+const musicBrainzRatePerSec = 1
+
+// durationTolerance is how far a fuzzy candidate's duration may drift from
+// the query's before it's rejected, per request.
+const durationTolerance = 3 * time.Second
+
+// titleScoreFloor is the minimum Levenshtein-based title similarity (0-1) a
+// fuzzy candidate needs to be accepted at all.
+const titleScoreFloor = 0.6
+
+// MusicBrainzSource resolves tracks against the MusicBrainz web service,
+// looking up by ISRC first and falling back to an artist+title fuzzy match
+// scored by Levenshtein distance and duration delta. Lookups (including
+// negative ones) are cached to disk so repeat runs over the same catalog
+// don't re-hit MusicBrainz, and every request shares one rate.Limiter so
+// concurrent harvester workers still respect the 1 req/sec ceiling as a
+// group rather than individually.
+type MusicBrainzSource struct {
+	rest    *resty.Client
+	limiter *rate.Limiter
+	cache   *diskCache
+}
+
+// NewMusicBrainzSource builds a MusicBrainzSource, loading any cache
+// already persisted at cachePath (pass "" to disable disk caching).
+// userAgent should identify this tool per MusicBrainz's API etiquette
+// (e.g. "spotify-playlist-dataset/1.0 (contact@example.com)").
+func NewMusicBrainzSource(cachePath, userAgent string) (*MusicBrainzSource, error) {
+	cache, err := newDiskCache(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	rest := resty.New().
+		SetBaseURL("https://musicbrainz.org/ws/2").
+		SetHeader("User-Agent", userAgent).
+		SetHeader("Accept", "application/json")
+	return &MusicBrainzSource{
+		rest:    rest,
+		limiter: rate.NewLimiter(rate.Limit(musicBrainzRatePerSec), 1),
+		cache:   cache,
+	}, nil
+}
+
+// Save flushes the lookup cache to disk. Callers should call this once
+// after a harvest pass, the same way enrich.FeatureFetcher.Save is used.
+func (s *MusicBrainzSource) Save() error {
+	return s.cache.save()
+}
+
+func (s *MusicBrainzSource) Resolve(ctx context.Context, q TrackQuery) (*Enrichment, error) {
+	key := cacheKey(q)
+	if entry, ok := s.cache.get(key); ok {
+		return entry.Enrichment, nil
+	}
+
+	enrichment, err := s.resolveUncached(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(key, cacheEntry{Found: enrichment != nil, Enrichment: enrichment})
+	return enrichment, nil
+}
+
+func cacheKey(q TrackQuery) string {
+	if q.ISRC != "" {
+		return "isrc:" + strings.ToLower(q.ISRC)
+	}
+	return "fuzzy:" + strings.ToLower(q.Artist) + "|" + strings.ToLower(q.Title)
+}
+
+func (s *MusicBrainzSource) resolveUncached(ctx context.Context, q TrackQuery) (*Enrichment, error) {
+	if q.ISRC != "" {
+		rec, err := s.searchRecording(ctx, fmt.Sprintf("isrc:%s", q.ISRC))
+		if err != nil {
+			return nil, err
+		}
+		if rec != nil {
+			return recordingToEnrichment(rec, "isrc", 1.0), nil
+		}
+	}
+	if q.Artist == "" || q.Title == "" {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf("artist:%q AND recording:%q", q.Artist, q.Title)
+	recs, err := s.searchRecordings(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	best, score := bestFuzzyMatch(recs, q)
+	if best == nil {
+		return nil, nil
+	}
+	return recordingToEnrichment(best, "fuzzy", score), nil
+}
+
+// bestFuzzyMatch picks the recording whose title is closest to q.Title (by
+// normalized Levenshtein distance) among candidates within
+// durationTolerance of q.Duration, rejecting anything below
+// titleScoreFloor.
+func bestFuzzyMatch(recs []mbRecording, q TrackQuery) (*mbRecording, float64) {
+	var best *mbRecording
+	bestScore := titleScoreFloor
+	for i := range recs {
+		rec := &recs[i]
+		if q.Duration > 0 && rec.Length > 0 {
+			delta := time.Duration(rec.Length) * time.Millisecond - q.Duration
+			if delta < 0 {
+				delta = -delta
+			}
+			if delta > durationTolerance {
+				continue
+			}
+		}
+		score := titleSimilarity(q.Title, rec.Title)
+		if score > bestScore {
+			bestScore = score
+			best = rec
+		}
+	}
+	return best, bestScore
+}
+
+// titleSimilarity turns Levenshtein edit distance into a 0-1 similarity
+// score so it can be compared against titleScoreFloor alongside the
+// duration filter.
+func titleSimilarity(a, b string) float64 {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein computes the edit distance between a and b.
+func levenshtein(a, b string) int {
+	if a == b {
+		return 0
+	}
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			del := prev[j] + 1
+			ins := curr[j-1] + 1
+			sub := prev[j-1] + cost
+			curr[j] = min3(del, ins, sub)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
+
+// mbRecording is the subset of MusicBrainz's recording resource this
+// package cares about.
+type mbRecording struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	Length       int    `json:"length"`
+	ArtistCredit []struct {
+		Artist struct {
+			ID string `json:"id"`
+		} `json:"artist"`
+	} `json:"artist-credit"`
+	ISRCs    []string `json:"isrcs"`
+	Releases []struct {
+		Date         string `json:"date"`
+		ReleaseGroup struct {
+			ID string `json:"id"`
+		} `json:"release-group"`
+		LabelInfo []struct {
+			Label struct {
+				Name string `json:"name"`
+			} `json:"label"`
+		} `json:"label-info"`
+	} `json:"releases"`
+	Tags []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+}
+
+type mbRecordingSearchResponse struct {
+	Recordings []mbRecording `json:"recordings"`
+}
+
+// searchRecording returns the first (highest-scored, since MusicBrainz
+// already sorts by its own relevance score) recording for query, or nil if
+// there were no hits.
+func (s *MusicBrainzSource) searchRecording(ctx context.Context, query string) (*mbRecording, error) {
+	recs, err := s.searchRecordings(ctx, query)
+	if err != nil || len(recs) == 0 {
+		return nil, err
+	}
+	return &recs[0], nil
+}
+
+func (s *MusicBrainzSource) searchRecordings(ctx context.Context, query string) ([]mbRecording, error) {
+	resp, err := s.execute(ctx, func(r *resty.Request) (*resty.Response, error) {
+		return r.SetContext(ctx).
+			SetQueryParams(map[string]string{
+				"query": query,
+				"fmt":   "json",
+				"inc":   "isrcs+releases+tags",
+				"limit": "5",
+			}).
+			Get("/recording")
+	})
+	if err != nil {
+		return nil, err
+	}
+	var parsed mbRecordingSearchResponse
+	if err := json.Unmarshal(resp.Body(), &parsed); err != nil {
+		return nil, fmt.Errorf("parse musicbrainz recording search: %w", err)
+	}
+	return parsed.Recordings, nil
+}
+
+// execute runs do while honoring the shared rate limiter and MusicBrainz's
+// Retry-After header on 503, mirroring spotifyClient.execute in
+// dynamic_retrieval.go.
+func (s *MusicBrainzSource) execute(ctx context.Context, do func(*resty.Request) (*resty.Response, error)) (*resty.Response, error) {
+	for {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := do(s.rest.R())
+		if err != nil {
+			return nil, fmt.Errorf("musicbrainz request: %w", err)
+		}
+		if resp.StatusCode() == http.StatusServiceUnavailable {
+			wait := 2 * time.Second
+			if v := resp.Header().Get("Retry-After"); v != "" {
+				if secs, err := strconv.Atoi(v); err == nil {
+					wait = time.Duration(secs) * time.Second
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+		if resp.IsError() {
+			return nil, fmt.Errorf("musicbrainz request failed with status %d", resp.StatusCode())
+		}
+		return resp, nil
+	}
+}
+
+func recordingToEnrichment(rec *mbRecording, method string, score float64) *Enrichment {
+	e := &Enrichment{RecordingMBID: rec.ID, MatchMethod: method, MatchScore: score}
+	if len(rec.ArtistCredit) > 0 {
+		e.ArtistMBID = rec.ArtistCredit[0].Artist.ID
+	}
+	if len(rec.ISRCs) > 0 {
+		e.ISRC = rec.ISRCs[0]
+	}
+	if len(rec.Releases) > 0 {
+		release := rec.Releases[0]
+		e.ReleaseGroupID = release.ReleaseGroup.ID
+		e.OriginalReleaseDate = release.Date
+		if len(release.LabelInfo) > 0 {
+			e.Label = release.LabelInfo[0].Label.Name
+		}
+	}
+	genres := make([]string, 0, len(rec.Tags))
+	for _, tag := range rec.Tags {
+		genres = append(genres, tag.Name)
+	}
+	e.Genres = genres
+	return e
+}
@@ -0,0 +1,43 @@
+// Package metrics exposes the Prometheus counters the harvester reports on
+// /metrics, so a multi-hour dataset job can be observed instead of running
+// blind.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	RequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "spotify_requests_total",
+		Help: "Spotify API requests made, by endpoint and response status.",
+	}, []string{"endpoint", "status"})
+
+	RateLimitedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "spotify_rate_limited_total",
+		Help: "Spotify API requests that received a 429 response.",
+	})
+
+	PlaylistsWrittenTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "playlists_written_total",
+		Help: "Playlists persisted to the dataset.",
+	})
+
+	TracksWrittenTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tracks_written_total",
+		Help: "Tracks persisted to the dataset.",
+	})
+)
+
+// Serve starts an HTTP server exposing /metrics on addr. It runs until the
+// listener fails; callers typically launch it with `go metrics.Serve(addr)`
+// and let the process exit kill it.
+func Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return http.ListenAndServe(addr, mux)
+}
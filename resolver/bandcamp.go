@@ -0,0 +1,123 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// bandcampRatePerSec throttles requests to Bandcamp's public search page.
+// Bandcamp publishes no documented rate limit for it, so this stays
+// conservative rather than risk tripping an anti-scraping defense.
+const bandcampRatePerSec = 1
+
+// bandcampScoreFloor is the minimum title+artist similarity (0-1) a search
+// result needs to be accepted as a match at all.
+const bandcampScoreFloor = 0.5
+
+// BandcampSource is a best-effort Bandcamp album finder: Bandcamp has no
+// public track-level search API, so it runs the public (unauthenticated)
+// bandcamp.com/search page and fuzzy-matches the results against a track's
+// artist+title, the same bridge pattern other Spotify-to-Bandcamp tools use.
+type BandcampSource struct {
+	http      *http.Client
+	limiter   *rate.Limiter
+	userAgent string
+}
+
+// NewBandcampSource builds a BandcampSource. userAgent identifies this tool
+// to Bandcamp, the same etiquette metadata.NewMusicBrainzSource follows.
+func NewBandcampSource(userAgent string) *BandcampSource {
+	return &BandcampSource{
+		http:      &http.Client{Timeout: 10 * time.Second},
+		limiter:   rate.NewLimiter(rate.Limit(bandcampRatePerSec), 1),
+		userAgent: userAgent,
+	}
+}
+
+// searchResultPattern pulls (url, title, artist) triples out of a
+// bandcamp.com/search results page. There is no JSON endpoint for this, so
+// scraping the public search HTML is the only option.
+var searchResultPattern = regexp.MustCompile(`(?s)<div class="itemurl">\s*<a[^>]*>([^<]+)</a>.*?<div class="heading">\s*<a[^>]*>([^<]+)</a>.*?<div class="subhead">([^<]*)</div>`)
+
+type bandcampResult struct {
+	URL    string
+	Title  string
+	Artist string
+}
+
+// FindAlbumURL returns the best-effort Bandcamp album URL for (artist,
+// title), and a 0-1 confidence, or "" with a zero confidence if nothing on
+// the results page cleared bandcampScoreFloor.
+func (s *BandcampSource) FindAlbumURL(ctx context.Context, artist, title string) (string, float64, error) {
+	if artist == "" && title == "" {
+		return "", 0, nil
+	}
+	if err := s.limiter.Wait(ctx); err != nil {
+		return "", 0, err
+	}
+	results, err := s.search(ctx, strings.TrimSpace(artist+" "+title))
+	if err != nil {
+		return "", 0, err
+	}
+	best, score := bestBandcampMatch(results, artist, title)
+	if best == nil {
+		return "", 0, nil
+	}
+	return best.URL, score, nil
+}
+
+func (s *BandcampSource) search(ctx context.Context, query string) ([]bandcampResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://bandcamp.com/search?q="+url.QueryEscape(query), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build bandcamp search request: %w", err)
+	}
+	req.Header.Set("User-Agent", s.userAgent)
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("bandcamp search: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bandcamp search returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read bandcamp search body: %w", err)
+	}
+
+	matches := searchResultPattern.FindAllStringSubmatch(string(body), -1)
+	results := make([]bandcampResult, 0, len(matches))
+	for _, m := range matches {
+		results = append(results, bandcampResult{
+			URL:    strings.TrimSpace(m[1]),
+			Title:  strings.TrimSpace(m[2]),
+			Artist: strings.TrimSpace(m[3]),
+		})
+	}
+	return results, nil
+}
+
+// bestBandcampMatch scores every result by the average title/artist
+// similarity to (artist, title), rejecting anything below
+// bandcampScoreFloor.
+func bestBandcampMatch(results []bandcampResult, artist, title string) (*bandcampResult, float64) {
+	var best *bandcampResult
+	bestScore := bandcampScoreFloor
+	for i := range results {
+		score := (titleSimilarity(results[i].Title, title) + titleSimilarity(results[i].Artist, artist)) / 2
+		if score > bestScore {
+			bestScore = score
+			best = &results[i]
+		}
+	}
+	return best, bestScore
+}
@@ -0,0 +1,148 @@
+// Package resolver maps harvested Spotify tracks to external identifiers -
+// ISRC, a MusicBrainz recording MBID, and a best-effort Bandcamp album URL -
+// so the dataset can be joined against non-Spotify music graphs. It's the
+// track-level counterpart to metadata.Source: where metadata resolves the
+// richer MusicBrainz fields (label, release date, genres) for the
+// --enable-musicbrainz harvester pass, resolver composes that same
+// MusicBrainz lookup with a Bandcamp match into one compact cross_refs row.
+package resolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kirbs-btw/spotify-playlist-dataset/dataset"
+	"github.com/kirbs-btw/spotify-playlist-dataset/metadata"
+)
+
+// TrackRef is a track queued for cross-service resolution.
+type TrackRef struct {
+	TrackID string
+	ISRC    string
+	Artist  string
+	Title   string
+}
+
+// Resolver resolves TrackRefs against MusicBrainz (for ISRC/MBID) and
+// Bandcamp (for a best-effort album URL), producing one
+// dataset.TableCrossRefs row per track.
+type Resolver struct {
+	musicBrainz metadata.Source
+	bandcamp    *BandcampSource
+}
+
+// New builds a Resolver from an already-constructed MusicBrainz source (see
+// metadata.NewMusicBrainzSource, which also handles its own 1 req/sec rate
+// limiting and disk caching) and Bandcamp source.
+func New(musicBrainz metadata.Source, bandcamp *BandcampSource) *Resolver {
+	return &Resolver{musicBrainz: musicBrainz, bandcamp: bandcamp}
+}
+
+// Resolve looks up ref against both sources and returns a
+// dataset.TableCrossRefs row. Either source missing a match just leaves its
+// columns empty rather than failing the whole row - this is a best-effort
+// enrichment pass over data that's already persisted.
+func (r *Resolver) Resolve(ctx context.Context, ref TrackRef) (dataset.Row, error) {
+	row := dataset.Row{
+		"spotify_track_id": ref.TrackID,
+		"isrc":             ref.ISRC,
+		"mbid":             "",
+		"bandcamp_url":     "",
+		"match_confidence": "0.0000",
+	}
+
+	mb, err := r.musicBrainz.Resolve(ctx, metadata.TrackQuery{ISRC: ref.ISRC, Title: ref.Title, Artist: ref.Artist})
+	if err != nil {
+		return nil, fmt.Errorf("musicbrainz resolve %s: %w", ref.TrackID, err)
+	}
+	confidence := 0.0
+	if mb != nil {
+		row["mbid"] = mb.RecordingMBID
+		if mb.ISRC != "" {
+			row["isrc"] = mb.ISRC
+		}
+		confidence = mb.MatchScore
+	}
+
+	bandcampURL, bandcampScore, err := r.bandcamp.FindAlbumURL(ctx, ref.Artist, ref.Title)
+	if err != nil {
+		return nil, fmt.Errorf("bandcamp resolve %s: %w", ref.TrackID, err)
+	}
+	if bandcampURL != "" {
+		row["bandcamp_url"] = bandcampURL
+		if bandcampScore > confidence {
+			confidence = bandcampScore
+		}
+	}
+
+	row["match_confidence"] = fmt.Sprintf("%.4f", confidence)
+	return row, nil
+}
+
+// titleSimilarity turns Levenshtein edit distance into a 0-1 similarity
+// score, the same measure metadata uses for its fuzzy match. Bandcamp
+// matches are held to bandcampScoreFloor (0.5), not metadata's 0.6 floor.
+func titleSimilarity(a, b string) float64 {
+	a, b = normalize(a), normalize(b)
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+func normalize(s string) string {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			out = append(out, r+('a'-'A'))
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			out = append(out, r)
+		case r == ' ':
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
+
+func levenshtein(a, b string) int {
+	if a == b {
+		return 0
+	}
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			del := prev[j] + 1
+			ins := curr[j-1] + 1
+			sub := prev[j-1] + cost
+			curr[j] = min3(del, ins, sub)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
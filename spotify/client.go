@@ -0,0 +1,283 @@
+// Package spotify wraps github.com/zmb3/spotify/v2 with the pagination and
+// retry behaviour the dataset-collection job needs: iterating every page of
+// a search result instead of the first 50 items, and honoring Spotify's
+// Retry-After header on 429 responses instead of giving up.
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// Client is a thin wrapper around *spotify.Client that adds retry/backoff
+// and page-iteration helpers on top of the typed zmb3 models.
+type Client struct {
+	api        *spotify.Client
+	maxRetries int
+}
+
+// NewClient authenticates with the Client Credentials flow and returns a
+// ready-to-use Client. Client Credentials tokens cannot see user data
+// (private playlists, saved tracks) - only public catalog endpoints.
+func NewClient(ctx context.Context, clientID, clientSecret string) (*Client, error) {
+	cfg := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     spotifyauth.TokenURL,
+	}
+	httpClient := cfg.Client(ctx)
+	return &Client{
+		api:        spotify.New(httpClient, spotify.WithRetry(true)),
+		maxRetries: 5,
+	}, nil
+}
+
+// NewClientFromHTTP wraps an already-authenticated http.Client, e.g. one
+// produced by the Authorization Code + PKCE flow in the auth package.
+func NewClientFromHTTP(httpClient *http.Client) *Client {
+	return &Client{api: spotify.New(httpClient, spotify.WithRetry(true)), maxRetries: 5}
+}
+
+// PlaylistPage is emitted once per page of results so callers can stream
+// results instead of buffering the whole search in memory.
+type PlaylistPage struct {
+	Playlists []spotify.SimplePlaylist
+	Total     int
+}
+
+// SearchPlaylists runs query and invokes onPage once per page of results,
+// following spotify.SearchResult.Playlists.Next until the API is exhausted.
+// It is the replacement for the old one-shot searchSpotify call, which threw
+// away every page after the first.
+func (c *Client) SearchPlaylists(ctx context.Context, query string, onPage func(PlaylistPage) error) error {
+	result, err := withRetry(ctx, c.maxRetries, func() (*spotify.SearchResult, error) {
+		return c.api.Search(ctx, query, spotify.SearchTypePlaylist, spotify.Limit(50))
+	})
+	if err != nil {
+		return fmt.Errorf("search %q: %w", query, err)
+	}
+
+	for {
+		if result == nil || result.Playlists == nil {
+			return nil
+		}
+		if err := onPage(PlaylistPage{Playlists: result.Playlists.Playlists, Total: int(result.Playlists.Total)}); err != nil {
+			return err
+		}
+		if result.Playlists.Next == "" {
+			return nil
+		}
+		next, err := withRetry(ctx, c.maxRetries, func() (*spotify.SearchResult, error) {
+			err := c.api.NextPage(ctx, result.Playlists)
+			return result, err
+		})
+		if err != nil {
+			return fmt.Errorf("search %q: next page: %w", query, err)
+		}
+		result = next
+	}
+}
+
+// CurrentUserPlaylists pages through the logged-in user's own and followed
+// playlists, invoking onPage once per page. This only works with a token
+// from the Authorization Code + PKCE flow - a client-credentials token has
+// no associated user and 401s on this endpoint - and is what lets a
+// --auth=user run see private/collaborative playlists a plain search never
+// would.
+func (c *Client) CurrentUserPlaylists(ctx context.Context, onPage func(PlaylistPage) error) error {
+	page, err := withRetry(ctx, c.maxRetries, func() (*spotify.SimplePlaylistPage, error) {
+		return c.api.CurrentUsersPlaylists(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("current user playlists: %w", err)
+	}
+	for {
+		if page == nil {
+			return nil
+		}
+		if err := onPage(PlaylistPage{Playlists: page.Playlists, Total: int(page.Total)}); err != nil {
+			return err
+		}
+		if page.Next == "" {
+			return nil
+		}
+		next, err := withRetry(ctx, c.maxRetries, func() (*spotify.SimplePlaylistPage, error) {
+			err := c.api.NextPage(ctx, page)
+			return page, err
+		})
+		if err != nil {
+			return fmt.Errorf("current user playlists: next page: %w", err)
+		}
+		page = next
+	}
+}
+
+// GetUserPlaylists pages through the public and collaborative playlists
+// owned or followed by userID, invoking onPage once per page. Unlike
+// SearchPlaylists, this lists a specific user's playlists directly rather
+// than free-text matching against the catalog, which is what lets the
+// crawler follow a playlist's owner to their other public playlists.
+func (c *Client) GetUserPlaylists(ctx context.Context, userID string, onPage func(PlaylistPage) error) error {
+	page, err := withRetry(ctx, c.maxRetries, func() (*spotify.SimplePlaylistPage, error) {
+		return c.api.GetPlaylistsForUser(ctx, userID)
+	})
+	if err != nil {
+		return fmt.Errorf("get playlists for user %s: %w", userID, err)
+	}
+	for {
+		if page == nil {
+			return nil
+		}
+		if err := onPage(PlaylistPage{Playlists: page.Playlists, Total: int(page.Total)}); err != nil {
+			return err
+		}
+		if page.Next == "" {
+			return nil
+		}
+		next, err := withRetry(ctx, c.maxRetries, func() (*spotify.SimplePlaylistPage, error) {
+			err := c.api.NextPage(ctx, page)
+			return page, err
+		})
+		if err != nil {
+			return fmt.Errorf("get playlists for user %s: next page: %w", userID, err)
+		}
+		page = next
+	}
+}
+
+// GetPlaylistTracks pages through every track on playlistID, invoking onPage
+// once per page of spotify.PlaylistTrack results.
+func (c *Client) GetPlaylistTracks(ctx context.Context, playlistID spotify.ID, onPage func([]spotify.PlaylistTrack) error) error {
+	page, err := withRetry(ctx, c.maxRetries, func() (*spotify.PlaylistTrackPage, error) {
+		return c.api.GetPlaylistTracks(ctx, playlistID)
+	})
+	if err != nil {
+		return fmt.Errorf("get playlist tracks %s: %w", playlistID, err)
+	}
+	for {
+		if page == nil {
+			return nil
+		}
+		if err := onPage(page.Tracks); err != nil {
+			return err
+		}
+		if page.Next == "" {
+			return nil
+		}
+		next, err := withRetry(ctx, c.maxRetries, func() (*spotify.PlaylistTrackPage, error) {
+			err := c.api.NextPage(ctx, page)
+			return page, err
+		})
+		if err != nil {
+			return fmt.Errorf("get playlist tracks %s: next page: %w", playlistID, err)
+		}
+		page = next
+	}
+}
+
+// GetPlaylist fetches full metadata for a single playlist by ID. The
+// crawler package only discovers playlist IDs during its search pass; this
+// fills in the name/owner/description fields dataset.TablePlaylists needs
+// before a row can be written for a discovered ID.
+func (c *Client) GetPlaylist(ctx context.Context, playlistID spotify.ID) (*spotify.FullPlaylist, error) {
+	pl, err := withRetry(ctx, c.maxRetries, func() (*spotify.FullPlaylist, error) {
+		return c.api.GetPlaylist(ctx, playlistID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get playlist %s: %w", playlistID, err)
+	}
+	return pl, nil
+}
+
+// maxAudioFeaturesBatch mirrors Spotify's /v1/audio-features?ids=... limit.
+const maxAudioFeaturesBatch = 100
+
+// GetAudioFeatures fetches audio features for trackIDs, chunking the
+// request into batches of maxAudioFeaturesBatch since the endpoint rejects
+// more IDs than that in one call.
+func (c *Client) GetAudioFeatures(ctx context.Context, trackIDs []spotify.ID) ([]*spotify.AudioFeatures, error) {
+	all := make([]*spotify.AudioFeatures, 0, len(trackIDs))
+	for start := 0; start < len(trackIDs); start += maxAudioFeaturesBatch {
+		end := start + maxAudioFeaturesBatch
+		if end > len(trackIDs) {
+			end = len(trackIDs)
+		}
+		batch, err := withRetry(ctx, c.maxRetries, func() ([]*spotify.AudioFeatures, error) {
+			return c.api.GetAudioFeatures(ctx, trackIDs[start:end]...)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get audio features [%d:%d]: %w", start, end, err)
+		}
+		all = append(all, batch...)
+	}
+	return all, nil
+}
+
+// maxArtistsBatch mirrors Spotify's /v1/artists?ids=... limit.
+const maxArtistsBatch = 50
+
+// GetArtists fetches artist metadata (including genres) for artistIDs,
+// chunking the request into batches of maxArtistsBatch since the endpoint
+// rejects more IDs than that in one call.
+func (c *Client) GetArtists(ctx context.Context, artistIDs []spotify.ID) ([]*spotify.FullArtist, error) {
+	all := make([]*spotify.FullArtist, 0, len(artistIDs))
+	for start := 0; start < len(artistIDs); start += maxArtistsBatch {
+		end := start + maxArtistsBatch
+		if end > len(artistIDs) {
+			end = len(artistIDs)
+		}
+		batch, err := withRetry(ctx, c.maxRetries, func() ([]*spotify.FullArtist, error) {
+			return c.api.GetArtists(ctx, artistIDs[start:end]...)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get artists [%d:%d]: %w", start, end, err)
+		}
+		all = append(all, batch...)
+	}
+	return all, nil
+}
+
+// GetAudioAnalysis fetches the (heavier) beat/segment analysis for a single
+// track. There is no batch endpoint for this, so callers should only call
+// it for tracks they actually need deep analysis for.
+func (c *Client) GetAudioAnalysis(ctx context.Context, trackID spotify.ID) (*spotify.AudioAnalysis, error) {
+	analysis, err := withRetry(ctx, c.maxRetries, func() (*spotify.AudioAnalysis, error) {
+		return c.api.GetAudioAnalysis(ctx, trackID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get audio analysis %s: %w", trackID, err)
+	}
+	return analysis, nil
+}
+
+// withRetry retries fn on rate-limit errors, backing off exponentially
+// between attempts. zmb3's spotify.Error surfaces Spotify's Retry-After
+// header as a time.Duration when spotify.WithRetry(true) is set, but we keep
+// this explicit loop too since pagination calls (NextPage) bypass the
+// library's internal retry.
+func withRetry[T any](ctx context.Context, maxRetries int, fn func() (T, error)) (T, error) {
+	var zero T
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		result, err := fn()
+		if err == nil {
+			return result, nil
+		}
+		rateErr, ok := err.(spotify.Error)
+		if !ok || rateErr.Status != http.StatusTooManyRequests || attempt >= maxRetries {
+			return zero, err
+		}
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,128 @@
+// Package spotifyid provides strongly-typed wrappers around the Spotify
+// catalog's base62 IDs (playlists, tracks, artists, albums), so a string
+// meant for one kind of entity can't silently be passed where another is
+// expected, and malformed IDs from user input are rejected at the edge
+// instead of producing a confusing 400 deep inside a harvest.
+package spotifyid
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// idPattern matches a bare Spotify ID: 22 base62 characters.
+var idPattern = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+
+// PlaylistID, TrackID, ArtistID and AlbumID are distinct types so the
+// compiler catches a playlist ID passed where a track ID is expected.
+type (
+	PlaylistID string
+	TrackID    string
+	ArtistID   string
+	AlbumID    string
+)
+
+func (id PlaylistID) String() string { return string(id) }
+func (id TrackID) String() string    { return string(id) }
+func (id ArtistID) String() string   { return string(id) }
+func (id AlbumID) String() string    { return string(id) }
+
+// ParsePlaylistID validates raw and normalizes a "spotify:playlist:..." URI
+// or an open.spotify.com URL down to a bare ID.
+func ParsePlaylistID(raw string) (PlaylistID, error) { return parse[PlaylistID](raw, "playlist id") }
+
+// MustParsePlaylistID is like ParsePlaylistID but panics on an invalid ID.
+// It exists for hardcoded seed IDs, where a failure is a programmer error.
+func MustParsePlaylistID(raw string) PlaylistID { return mustParse[PlaylistID](raw, "playlist id") }
+
+// ParseTrackID validates raw and normalizes a "spotify:track:..." URI or an
+// open.spotify.com URL down to a bare ID.
+func ParseTrackID(raw string) (TrackID, error) { return parse[TrackID](raw, "track id") }
+
+// MustParseTrackID is like ParseTrackID but panics on an invalid ID.
+func MustParseTrackID(raw string) TrackID { return mustParse[TrackID](raw, "track id") }
+
+// ParseArtistID validates raw and normalizes a "spotify:artist:..." URI or
+// an open.spotify.com URL down to a bare ID.
+func ParseArtistID(raw string) (ArtistID, error) { return parse[ArtistID](raw, "artist id") }
+
+// MustParseArtistID is like ParseArtistID but panics on an invalid ID.
+func MustParseArtistID(raw string) ArtistID { return mustParse[ArtistID](raw, "artist id") }
+
+// ParseAlbumID validates raw and normalizes a "spotify:album:..." URI or an
+// open.spotify.com URL down to a bare ID.
+func ParseAlbumID(raw string) (AlbumID, error) { return parse[AlbumID](raw, "album id") }
+
+// MustParseAlbumID is like ParseAlbumID but panics on an invalid ID.
+func MustParseAlbumID(raw string) AlbumID { return mustParse[AlbumID](raw, "album id") }
+
+func (id *PlaylistID) UnmarshalJSON(data []byte) error { return unmarshal(data, id, "playlist id") }
+func (id *TrackID) UnmarshalJSON(data []byte) error    { return unmarshal(data, id, "track id") }
+func (id *ArtistID) UnmarshalJSON(data []byte) error   { return unmarshal(data, id, "artist id") }
+func (id *AlbumID) UnmarshalJSON(data []byte) error    { return unmarshal(data, id, "album id") }
+
+// parse normalizes and validates raw into a typed ID. It's a free function
+// rather than a method because Go methods can't introduce their own type
+// parameters.
+func parse[T ~string](raw, label string) (T, error) {
+	normalized, err := normalize(raw)
+	if err != nil {
+		return T(""), fmt.Errorf("%s %q: %w", label, raw, err)
+	}
+	return T(normalized), nil
+}
+
+func mustParse[T ~string](raw, label string) T {
+	id, err := parse[T](raw, label)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// unmarshal backs every typed ID's UnmarshalJSON hook, so a malformed ID in
+// a user-supplied --seeds file fails fast with a precise error instead of
+// surfacing as a 400 once the harvest reaches Spotify.
+func unmarshal[T ~string](data []byte, out *T, label string) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%s: %w", label, err)
+	}
+	if raw == "" {
+		*out = T("")
+		return nil
+	}
+	id, err := parse[T](raw, label)
+	if err != nil {
+		return err
+	}
+	*out = id
+	return nil
+}
+
+// normalize strips a "spotify:<kind>:<id>" URI or an open.spotify.com URL
+// down to the bare ID, then validates the 22-char base62 format.
+func normalize(raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+
+	switch {
+	case strings.HasPrefix(value, "spotify:"):
+		parts := strings.Split(value, ":")
+		value = parts[len(parts)-1]
+	case strings.Contains(value, "open.spotify.com"):
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("parse spotify url: %w", err)
+		}
+		segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+		value = segments[len(segments)-1]
+	}
+
+	if !idPattern.MatchString(value) {
+		return "", fmt.Errorf("invalid spotify id format, want 22 base62 characters")
+	}
+	return value, nil
+}
@@ -0,0 +1,79 @@
+package spotifyid
+
+import "testing"
+
+func TestParseTrackID(t *testing.T) {
+	const bare = "06AKEBrKUckW0KREUWRnvT"
+
+	cases := []struct {
+		name string
+		raw  string
+	}{
+		{"bare id", bare},
+		{"uri", "spotify:track:" + bare},
+		{"url", "https://open.spotify.com/track/" + bare},
+		{"url with query params", "https://open.spotify.com/track/" + bare + "?si=abc123"},
+		{"padded with whitespace", "  " + bare + "  "},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := ParseTrackID(tc.raw)
+			if err != nil {
+				t.Fatalf("ParseTrackID(%q): %v", tc.raw, err)
+			}
+			if id.String() != bare {
+				t.Errorf("ParseTrackID(%q) = %q, want %q", tc.raw, id.String(), bare)
+			}
+		})
+	}
+}
+
+func TestParseTrackIDInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"too-short",
+		"06AKEBrKUckW0KREUWRnvT!",
+		"https://open.spotify.com/track/",
+	}
+	for _, raw := range cases {
+		if _, err := ParseTrackID(raw); err == nil {
+			t.Errorf("ParseTrackID(%q) succeeded, want error", raw)
+		}
+	}
+}
+
+func TestMustParseTrackIDPanicsOnInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParseTrackID did not panic on an invalid id")
+		}
+	}()
+	MustParseTrackID("not-an-id")
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var id TrackID
+	if err := id.UnmarshalJSON([]byte(`"spotify:track:06AKEBrKUckW0KREUWRnvT"`)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if id.String() != "06AKEBrKUckW0KREUWRnvT" {
+		t.Errorf("UnmarshalJSON set id = %q, want normalized bare id", id.String())
+	}
+}
+
+func TestUnmarshalJSONEmptyIsZeroValue(t *testing.T) {
+	var id TrackID
+	if err := id.UnmarshalJSON([]byte(`""`)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if id != "" {
+		t.Errorf("UnmarshalJSON(\"\") = %q, want empty id", id)
+	}
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	var id TrackID
+	if err := id.UnmarshalJSON([]byte(`"not-an-id"`)); err == nil {
+		t.Error("UnmarshalJSON(\"not-an-id\") succeeded, want error")
+	}
+}
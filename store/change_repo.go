@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type sqliteChangeRepo struct {
+	ex dbExecutor
+}
+
+func (r *sqliteChangeRepo) Record(ctx context.Context, c PlaylistChange) error {
+	_, err := r.ex.ExecContext(ctx, `
+		INSERT INTO playlist_changes (playlist_id, added_track_ids, removed_track_ids, run_at) VALUES (?, ?, ?, ?)
+	`, c.PlaylistID, strings.Join(c.AddedTrackIDs, "|"), strings.Join(c.RemovedTrackIDs, "|"), c.RunAt)
+	if err != nil {
+		return fmt.Errorf("record playlist_changes for %s: %w", c.PlaylistID, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+type sqlitePlaylistRepo struct {
+	ex dbExecutor
+}
+
+func (r *sqlitePlaylistRepo) Upsert(ctx context.Context, p Playlist) error {
+	_, err := r.ex.ExecContext(ctx, `
+		INSERT INTO playlists (id, name, description, followers, public, collaborative, owner_id, owner_name, origin_source, origin_query, score, snapshot_id, image_url, track_total, last_refreshed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			followers = excluded.followers,
+			public = excluded.public,
+			collaborative = excluded.collaborative,
+			owner_id = excluded.owner_id,
+			owner_name = excluded.owner_name,
+			origin_source = excluded.origin_source,
+			origin_query = excluded.origin_query,
+			score = excluded.score,
+			snapshot_id = excluded.snapshot_id,
+			image_url = excluded.image_url,
+			track_total = excluded.track_total,
+			last_refreshed_at = excluded.last_refreshed_at
+	`,
+		p.ID, p.Name, p.Description, p.Followers, p.Public, p.Collaborative,
+		p.OwnerID, p.OwnerName, p.OriginSource, p.OriginQuery, p.Score,
+		p.SnapshotID, p.ImageURL, p.TrackTotal, p.LastRefreshedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert playlist %s: %w", p.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+type sqliteSeedRepo struct {
+	ex dbExecutor
+}
+
+func (r *sqliteSeedRepo) MarkRun(ctx context.Context, query, source string) error {
+	_, err := r.ex.ExecContext(ctx, `
+		INSERT INTO seeds (query, source, last_run_at) VALUES (?, ?, datetime('now'))
+		ON CONFLICT(query, source) DO UPDATE SET last_run_at = excluded.last_run_at
+	`, query, source)
+	if err != nil {
+		return fmt.Errorf("mark seed %q/%q run: %w", source, query, err)
+	}
+	return nil
+}
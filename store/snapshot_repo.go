@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type sqliteSnapshotRepo struct {
+	ex dbExecutor
+}
+
+func (r *sqliteSnapshotRepo) Get(ctx context.Context, playlistID string) (string, bool, error) {
+	var snapshotID string
+	err := r.ex.QueryRowContext(ctx, `SELECT snapshot_id FROM snapshots WHERE playlist_id = ?`, playlistID).Scan(&snapshotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("get snapshot for %s: %w", playlistID, err)
+	}
+	return snapshotID, true, nil
+}
+
+func (r *sqliteSnapshotRepo) Set(ctx context.Context, playlistID, snapshotID string) error {
+	_, err := r.ex.ExecContext(ctx, `
+		INSERT INTO snapshots (playlist_id, snapshot_id, updated_at) VALUES (?, ?, datetime('now'))
+		ON CONFLICT(playlist_id) DO UPDATE SET snapshot_id = excluded.snapshot_id, updated_at = excluded.updated_at
+	`, playlistID, snapshotID)
+	if err != nil {
+		return fmt.Errorf("set snapshot for %s: %w", playlistID, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// dbExecutor is satisfied by both *sql.DB and *sql.Tx, so the repositories
+// below work unchanged whether they're running against the pool or inside
+// a WithTx transaction.
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// sqliteStore is the modernc.org/sqlite-backed DataStore. There's no CGO
+// dependency, at the cost of single-connection writes: NewSQLite caps the
+// pool at one connection, so concurrent callers queue for it.
+type sqliteStore struct {
+	db  *sql.DB
+	ex  dbExecutor
+	ptr *sql.DB // non-nil only on the root store, so Close and WithTx know they own db
+}
+
+// NewSQLite opens (creating if needed) a SQLite database at path, applies
+// any pending migrations, and returns a DataStore backed by it.
+func NewSQLite(path string) (DataStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1) // modernc.org/sqlite connections aren't safe to share across goroutines
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate %s: %w", path, err)
+	}
+	return &sqliteStore{db: db, ex: db, ptr: db}, nil
+}
+
+func (s *sqliteStore) Playlist() PlaylistRepository { return &sqlitePlaylistRepo{ex: s.ex} }
+func (s *sqliteStore) Track() TrackRepository       { return &sqliteTrackRepo{ex: s.ex} }
+func (s *sqliteStore) Snapshot() SnapshotRepository { return &sqliteSnapshotRepo{ex: s.ex} }
+func (s *sqliteStore) Seed() SeedRepository         { return &sqliteSeedRepo{ex: s.ex} }
+func (s *sqliteStore) Change() ChangeRepository     { return &sqliteChangeRepo{ex: s.ex} }
+
+func (s *sqliteStore) WithTx(ctx context.Context, fn func(tx DataStore) error) error {
+	if s.ptr == nil {
+		return fmt.Errorf("nested WithTx is not supported")
+	}
+	tx, err := s.ptr.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if err := fn(&sqliteStore{db: s.db, ex: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Close() error {
+	if s.ptr == nil {
+		return nil
+	}
+	return s.ptr.Close()
+}
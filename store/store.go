@@ -0,0 +1,120 @@
+// Package store is a repository-style persistence layer for the harvested
+// dataset, replacing ad hoc SQL scattered across the scripts package's
+// sinks. It follows the shape of Navidrome's model.DataStore: a DataStore
+// hands out narrow, entity-scoped repositories, and WithTx runs a closure
+// against a transaction-scoped DataStore so a caller can touch several
+// repositories atomically without threading a *sql.Tx through every call.
+package store
+
+import "context"
+
+// Playlist is the persisted form of a harvested playlist.
+type Playlist struct {
+	ID              string
+	Name            string
+	Description     string
+	Followers       int
+	Public          bool
+	Collaborative   bool
+	OwnerID         string
+	OwnerName       string
+	OriginSource    string
+	OriginQuery     string
+	Score           float64
+	SnapshotID      string
+	ImageURL        string
+	TrackTotal      int
+	LastRefreshedAt string
+}
+
+// Track is a track referenced by one or more harvested playlists.
+type Track struct {
+	ID      string
+	Name    string
+	AlbumID string
+}
+
+// Artist is an artist credited on a Track.
+type Artist struct {
+	ID   string
+	Name string
+}
+
+// PlaylistTrack is one row of a playlist's track listing, in playlist order.
+type PlaylistTrack struct {
+	PlaylistID string
+	TrackID    string
+	Position   int
+	AddedAt    string
+	AddedBy    string
+}
+
+// PlaylistRepository persists Playlist rows.
+type PlaylistRepository interface {
+	// Upsert inserts p or, if its ID already exists, overwrites every
+	// column with p's values.
+	Upsert(ctx context.Context, p Playlist) error
+}
+
+// TrackRepository persists Track, Artist and playlist-track-listing rows.
+type TrackRepository interface {
+	Upsert(ctx context.Context, t Track) error
+	UpsertArtist(ctx context.Context, a Artist) error
+	LinkArtist(ctx context.Context, trackID, artistID string) error
+	// ReplaceListing replaces playlistID's entire track listing with items,
+	// since a changed snapshot means the list may have been reordered or
+	// edited and there's no cheap way to diff individual rows.
+	ReplaceListing(ctx context.Context, playlistID string, items []PlaylistTrack) error
+	// ListingFor returns playlistID's currently-stored track listing (i.e.
+	// as of the previous run), so a caller can diff it against a freshly
+	// fetched listing before ReplaceListing overwrites it.
+	ListingFor(ctx context.Context, playlistID string) ([]PlaylistTrack, error)
+}
+
+// PlaylistChange is one recorded drift between two harvests of the same
+// playlist: which tracks appeared and which disappeared when its
+// SnapshotID moved.
+type PlaylistChange struct {
+	PlaylistID      string
+	AddedTrackIDs   []string
+	RemovedTrackIDs []string
+	RunAt           string
+}
+
+// ChangeRepository persists PlaylistChange rows, so a scheduled resync can
+// report what actually moved rather than just that a playlist's snapshot
+// changed.
+type ChangeRepository interface {
+	Record(ctx context.Context, c PlaylistChange) error
+}
+
+// SnapshotRepository tracks the last-seen snapshot_id per playlist, so a
+// harvest can skip playlists Spotify reports as unchanged. It's the
+// SQLite-backed analogue of the JSON-file snapshotCache in dynamic_retrieval.go.
+type SnapshotRepository interface {
+	Get(ctx context.Context, playlistID string) (snapshotID string, ok bool, err error)
+	Set(ctx context.Context, playlistID, snapshotID string) error
+}
+
+// SeedRepository records which seed queries have been run and when, so a
+// future pass could prioritize seeds that haven't run recently. Harvesting
+// itself still reads its seed list from harvestSeeds; this is bookkeeping.
+type SeedRepository interface {
+	MarkRun(ctx context.Context, query, source string) error
+}
+
+// DataStore hands out the repositories above and coordinates transactions
+// across them.
+type DataStore interface {
+	Playlist() PlaylistRepository
+	Track() TrackRepository
+	Snapshot() SnapshotRepository
+	Seed() SeedRepository
+	Change() ChangeRepository
+
+	// WithTx runs fn against a DataStore whose repositories all share one
+	// transaction, committing if fn returns nil and rolling back otherwise.
+	WithTx(ctx context.Context, fn func(tx DataStore) error) error
+
+	Close() error
+}
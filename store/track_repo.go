@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+type sqliteTrackRepo struct {
+	ex dbExecutor
+}
+
+func (r *sqliteTrackRepo) Upsert(ctx context.Context, t Track) error {
+	_, err := r.ex.ExecContext(ctx, `
+		INSERT INTO tracks (id, name, album_id) VALUES (?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, album_id = excluded.album_id
+	`, t.ID, t.Name, t.AlbumID)
+	if err != nil {
+		return fmt.Errorf("upsert track %s: %w", t.ID, err)
+	}
+	return nil
+}
+
+func (r *sqliteTrackRepo) UpsertArtist(ctx context.Context, a Artist) error {
+	_, err := r.ex.ExecContext(ctx, `
+		INSERT INTO artists (id, name) VALUES (?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name
+	`, a.ID, a.Name)
+	if err != nil {
+		return fmt.Errorf("upsert artist %s: %w", a.ID, err)
+	}
+	return nil
+}
+
+func (r *sqliteTrackRepo) LinkArtist(ctx context.Context, trackID, artistID string) error {
+	_, err := r.ex.ExecContext(ctx, `
+		INSERT INTO track_artists (track_id, artist_id) VALUES (?, ?)
+		ON CONFLICT(track_id, artist_id) DO NOTHING
+	`, trackID, artistID)
+	if err != nil {
+		return fmt.Errorf("link track %s to artist %s: %w", trackID, artistID, err)
+	}
+	return nil
+}
+
+func (r *sqliteTrackRepo) ListingFor(ctx context.Context, playlistID string) ([]PlaylistTrack, error) {
+	rows, err := r.ex.QueryContext(ctx, `
+		SELECT playlist_id, track_id, position, added_at, added_by FROM playlist_tracks
+		WHERE playlist_id = ? ORDER BY position
+	`, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("list tracks for %s: %w", playlistID, err)
+	}
+	defer rows.Close()
+
+	var items []PlaylistTrack
+	for rows.Next() {
+		var item PlaylistTrack
+		if err := rows.Scan(&item.PlaylistID, &item.TrackID, &item.Position, &item.AddedAt, &item.AddedBy); err != nil {
+			return nil, fmt.Errorf("scan track listing row for %s: %w", playlistID, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tracks for %s: %w", playlistID, err)
+	}
+	return items, nil
+}
+
+func (r *sqliteTrackRepo) ReplaceListing(ctx context.Context, playlistID string, items []PlaylistTrack) error {
+	if _, err := r.ex.ExecContext(ctx, `DELETE FROM playlist_tracks WHERE playlist_id = ?`, playlistID); err != nil {
+		return fmt.Errorf("clear playlist_tracks for %s: %w", playlistID, err)
+	}
+	for _, item := range items {
+		if _, err := r.ex.ExecContext(ctx, `
+			INSERT INTO playlist_tracks (playlist_id, track_id, position, added_at, added_by) VALUES (?, ?, ?, ?, ?)
+		`, playlistID, item.TrackID, item.Position, item.AddedAt, item.AddedBy); err != nil {
+			return fmt.Errorf("insert playlist_tracks row for %s: %w", playlistID, err)
+		}
+	}
+	return nil
+}